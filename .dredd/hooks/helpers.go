@@ -262,10 +262,12 @@ func randomString(strlen int) string {
 func loadConfig() {
 	cwd, _ := os.Getwd()
 	file, _ := os.Open(cwd + "/.dredd/config.json")
-	if err := json.NewDecoder(file).Decode(&util.Config); err != nil {
+	var config *util.ConfigType
+	if err := json.NewDecoder(file).Decode(&config); err != nil {
 		fmt.Println("Could not decode configuration!")
 		panic(err)
 	}
+	util.SetConfig(config)
 }
 
 var store db.Store
@@ -3,8 +3,17 @@ package api
 import (
 	//_ "github.com/snikch/goodman/hooks"
 	//_ "github.com/snikch/goodman/transaction"
+	gocontext "context"
+	"errors"
+	"github.com/ansible-semaphore/semaphore/db"
+	"github.com/ansible-semaphore/semaphore/db/bolt"
+	"github.com/ansible-semaphore/semaphore/util"
+	"github.com/gorilla/context"
+	"github.com/gorilla/handlers"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
 )
 
@@ -20,3 +29,158 @@ func TestApiPing(t *testing.T) {
 		t.Errorf("Response code should be 200 %d", rr.Code)
 	}
 }
+
+// failingPingStore is a store of unavailable database.
+type failingPingStore struct {
+	db.Store
+}
+
+func (failingPingStore) PermanentConnection() bool {
+	return true
+}
+
+func (failingPingStore) Ping(ctx gocontext.Context) error {
+	return errors.New("connection refused")
+}
+
+func TestApiPingHealthCheckDB(t *testing.T) {
+	config := util.Config()
+	defer func() { util.SetConfig(config) }()
+	util.SetConfig(&util.ConfigType{HealthCheckDB: true})
+
+	var store db.Store = bolt.CreateTestStore()
+
+	r := Route()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			context.Set(r, "store", store)
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	req, _ := http.NewRequest("GET", "/api/ping", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != 200 {
+		t.Errorf("Response code should be 200 %d", rr.Code)
+	}
+
+	store = failingPingStore{}
+
+	req, _ = http.NewRequest("GET", "/api/ping", nil)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Response code should be 503 %d", rr.Code)
+	}
+}
+
+func TestLoginRateLimitMiddleware(t *testing.T) {
+	config := util.Config()
+	defer func() { util.SetConfig(config) }()
+	util.SetConfig(&util.ConfigType{LoginRateLimit: 2, LoginRateWindow: 60})
+
+	status := http.StatusUnauthorized
+	handler := loginRateLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+
+	doLogin := func(remoteAddr string) int {
+		req, _ := http.NewRequest("POST", "/api/auth/login", nil)
+		req.RemoteAddr = remoteAddr
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	for i := 0; i < 2; i++ {
+		if code := doLogin("10.0.0.1:1234"); code != http.StatusUnauthorized {
+			t.Fatalf("Attempt %d must not be limited, got %d", i+1, code)
+		}
+	}
+
+	if code := doLogin("10.0.0.1:1235"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected %d after too many failed attempts, got %d", http.StatusTooManyRequests, code)
+	}
+
+	if code := doLogin("10.0.0.2:1234"); code != http.StatusUnauthorized {
+		t.Errorf("Other IP address must not be limited, got %d", code)
+	}
+
+	loginLimiter.reset("10.0.0.1")
+	status = http.StatusNoContent
+	if code := doLogin("10.0.0.1:1234"); code != http.StatusNoContent {
+		t.Errorf("Expected successful login after reset, got %d", code)
+	}
+}
+
+func TestLoginRateLimitMiddlewareForwardedFor(t *testing.T) {
+	config := util.Config()
+	defer func() { util.SetConfig(config) }()
+	util.SetConfig(&util.ConfigType{LoginRateLimit: 2, LoginRateWindow: 60})
+
+	limited := handlers.ProxyHeaders(loginRateLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})))
+
+	doLogin := func(remoteAddr string, forwardedFor string) int {
+		req, _ := http.NewRequest("POST", "/api/auth/login", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rr := httptest.NewRecorder()
+		limited.ServeHTTP(rr, WithPeerAddr(req))
+		return rr.Code
+	}
+
+	for i := 0; i < 2; i++ {
+		doLogin("10.0.1.1:1234", "192.168.0."+strconv.Itoa(i))
+	}
+
+	if code := doLogin("10.0.1.1:1234", "192.168.0.100"); code != http.StatusTooManyRequests {
+		t.Errorf("Spoofed X-Forwarded-For from untrusted peer must not bypass the limit, got %d", code)
+	}
+
+	util.Config().TrustedProxies = []string{"10.0.1.2"}
+
+	for i := 0; i < 2; i++ {
+		doLogin("10.0.1.2:1234", "192.168.1.1")
+	}
+
+	if code := doLogin("10.0.1.2:1234", "192.168.1.2"); code != http.StatusUnauthorized {
+		t.Errorf("Clients behind trusted proxy must be limited separately, got %d", code)
+	}
+
+	if code := doLogin("10.0.1.2:1234", "192.168.1.1"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected %d for client behind trusted proxy, got %d", http.StatusTooManyRequests, code)
+	}
+}
+
+func TestRouteWebRootWithWebHost(t *testing.T) {
+	config := util.Config()
+	webHostURL := util.WebHostURL
+	defer func() {
+		util.SetConfig(config)
+		util.WebHostURL = webHostURL
+	}()
+
+	for _, webHost := range []string{"https://tools.example.com", "https://tools.example.com/semaphore"} {
+		util.SetConfig(&util.ConfigType{WebHost: webHost, WebRoot: "/semaphore"})
+		util.WebHostURL, _ = url.Parse(webHost)
+
+		handler := http.StripPrefix("/semaphore", Route())
+
+		req, _ := http.NewRequest("GET", "/semaphore/api/ping", nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("Response code should be 200 for web host %v, got %d", webHost, rr.Code)
+		}
+
+		if apiHost := util.Config().GetApiHost(); apiHost != "https://tools.example.com/semaphore" {
+			t.Errorf("API host must contain web root for web host %v, got %v", webHost, apiHost)
+		}
+	}
+}
@@ -61,9 +61,10 @@ func authenticationHandler(w http.ResponseWriter, r *http.Request) bool {
 			return false
 		}
 
-		if time.Since(session.LastActive).Hours() > 7*24 {
-			// more than week old unused session
-			// destroy.
+		if time.Since(session.LastActive).Hours() > 7*24 ||
+			time.Since(session.Created) > util.Config().GetSessionLifetime() {
+			// more than week old unused session or session
+			// older than configured lifetime, destroy.
 			if err := helpers.Store(r).ExpireSession(userID, sessionID); err != nil {
 				// it is internal error, it doesn't concern the user
 				log.Error(err)
@@ -2,6 +2,7 @@ package helpers
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/ansible-semaphore/semaphore/services/tasks"
 	"net/http"
 	"net/url"
@@ -13,6 +14,7 @@ import (
 	"github.com/gorilla/context"
 
 	"github.com/ansible-semaphore/semaphore/db"
+	"github.com/ansible-semaphore/semaphore/util"
 
 	"github.com/gorilla/mux"
 )
@@ -37,7 +39,7 @@ func GetIntParam(name string, w http.ResponseWriter, r *http.Request) (int, erro
 
 	if err != nil {
 		if !isXHR(w, r) {
-			http.Redirect(w, r, "/404", http.StatusFound)
+			http.Redirect(w, r, util.Config().GetWebPath("/404"), http.StatusFound)
 		} else {
 			w.WriteHeader(http.StatusBadRequest)
 		}
@@ -55,7 +57,12 @@ type H map[string]interface{}
 func Bind(w http.ResponseWriter, r *http.Request, out interface{}) bool {
 	err := json.NewDecoder(r.Body).Decode(out)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		} else {
+			w.WriteHeader(http.StatusBadRequest)
+		}
 	}
 
 	return err == nil
@@ -3,8 +3,10 @@ package helpers
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/ansible-semaphore/semaphore/util"
 	"github.com/gorilla/mux"
 )
 
@@ -21,6 +23,24 @@ func TestGetIntParam(t *testing.T) {
 	}
 }
 
+func TestGetIntParamRedirectWebRoot(t *testing.T) {
+	config := util.Config()
+	defer func() { util.SetConfig(config) }()
+	util.SetConfig(&util.ConfigType{WebRoot: "/semaphore"})
+
+	req, _ := http.NewRequest("GET", "/test/abc", nil)
+	req.Header.Set("Accept", "text/html")
+	rr := httptest.NewRecorder()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/test/{test_id}", mockParam)
+	r.ServeHTTP(rr, req)
+
+	if location := rr.Header().Get("Location"); location != "/semaphore/404" {
+		t.Errorf("Redirect must be prefixed by WebRoot, got %v", location)
+	}
+}
+
 func mockParam(w http.ResponseWriter, r *http.Request) {
 	_, err := GetIntParam("test_id", w, r)
 	if err != nil {
@@ -29,3 +49,20 @@ func mockParam(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(200)
 }
+
+func TestBindTooLargeBody(t *testing.T) {
+	req, _ := http.NewRequest("POST", "/test", strings.NewReader(`{"name": "too long name"}`))
+	rr := httptest.NewRecorder()
+	req.Body = http.MaxBytesReader(rr, req.Body, 10)
+
+	var out struct {
+		Name string `json:"name"`
+	}
+	if Bind(rr, req, &out) {
+		t.Fatal("Body larger than limit must not be decoded")
+	}
+
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Response code should be 413 %d", rr.Code)
+	}
+}
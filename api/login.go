@@ -25,19 +25,33 @@ import (
 	"github.com/ansible-semaphore/semaphore/util"
 )
 
+// getLDAPAttributeValue returns value of the first non-empty attribute
+// from the comma-separated list of attributes.
+func getLDAPAttributeValue(entry *ldap.Entry, mapping string) string {
+	for _, attr := range util.SplitLdapMapping(mapping) {
+		if value := entry.GetAttributeValue(attr); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
 func tryFindLDAPUser(username, password string) (*db.User, error) {
-	if !util.Config.LdapEnable {
+	if !util.Config().LdapEnable {
 		return nil, fmt.Errorf("LDAP not configured")
 	}
 
 	var l *ldap.Conn
 	var err error
-	if util.Config.LdapNeedTLS {
-		l, err = ldap.DialTLS("tcp", util.Config.LdapServer, &tls.Config{
-			InsecureSkipVerify: true,
-		})
+	if util.Config().LdapNeedTLS {
+		var tlsConfig *tls.Config
+		tlsConfig, err = util.Config().GetLdapTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		l, err = ldap.DialTLS("tcp", util.Config().LdapServer, tlsConfig)
 	} else {
-		l, err = ldap.Dial("tcp", util.Config.LdapServer)
+		l, err = ldap.Dial("tcp", util.Config().LdapServer)
 	}
 
 	if err != nil {
@@ -46,16 +60,16 @@ func tryFindLDAPUser(username, password string) (*db.User, error) {
 	defer l.Close()
 
 	// First bind with a read only user
-	if err = l.Bind(util.Config.LdapBindDN, util.Config.LdapBindPassword); err != nil {
+	if err = l.Bind(util.Config().LdapBindDN, util.Config().LdapBindPassword); err != nil {
 		return nil, err
 	}
 
 	// Search for the given username
 	searchRequest := ldap.NewSearchRequest(
-		util.Config.LdapSearchDN,
+		util.Config().LdapSearchDN,
 		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
-		fmt.Sprintf(util.Config.LdapSearchFilter, username),
-		[]string{util.Config.LdapMappings.DN},
+		fmt.Sprintf(util.Config().LdapSearchFilter, username),
+		util.SplitLdapMapping(util.Config().LdapMappings.DN),
 		nil,
 	)
 
@@ -88,16 +102,16 @@ func tryFindLDAPUser(username, password string) (*db.User, error) {
 	}
 
 	// Second time bind as read only user
-	if err = l.Bind(util.Config.LdapBindDN, util.Config.LdapBindPassword); err != nil {
+	if err = l.Bind(util.Config().LdapBindDN, util.Config().LdapBindPassword); err != nil {
 		return nil, err
 	}
 
 	// Get user info
 	searchRequest = ldap.NewSearchRequest(
-		util.Config.LdapSearchDN,
+		util.Config().LdapSearchDN,
 		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
-		fmt.Sprintf(util.Config.LdapSearchFilter, username),
-		[]string{util.Config.LdapMappings.DN, util.Config.LdapMappings.Mail, util.Config.LdapMappings.UID, util.Config.LdapMappings.CN},
+		fmt.Sprintf(util.Config().LdapSearchFilter, username),
+		util.Config().LdapMappings.GetAttributes(),
 		nil,
 	)
 
@@ -111,14 +125,29 @@ func tryFindLDAPUser(username, password string) (*db.User, error) {
 	}
 
 	ldapUser := db.User{
-		Username: strings.ToLower(sr.Entries[0].GetAttributeValue(util.Config.LdapMappings.UID)),
+		Username: strings.ToLower(getLDAPAttributeValue(sr.Entries[0], util.Config().LdapMappings.UID)),
 		Created:  time.Now(),
-		Name:     sr.Entries[0].GetAttributeValue(util.Config.LdapMappings.CN),
-		Email:    sr.Entries[0].GetAttributeValue(util.Config.LdapMappings.Mail),
+		Name:     getLDAPAttributeValue(sr.Entries[0], util.Config().LdapMappings.CN),
+		Email:    getLDAPAttributeValue(sr.Entries[0], util.Config().LdapMappings.Mail),
 		External: true,
 		Alert:    false,
 	}
 
+	if util.Config().LdapGroupSearchDN != "" {
+		var groups []string
+		groups, err = findLDAPUserGroups(l, userdn)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(groups) == 0 {
+			log.Warn("User " + username + " is not a member of any allowed LDAP group")
+			return nil, nil
+		}
+
+		ldapUser.Admin = isLDAPAdminGroupMember(groups)
+	}
+
 	err = db.ValidateUser(ldapUser)
 
 	if err != nil {
@@ -131,6 +160,47 @@ func tryFindLDAPUser(username, password string) (*db.User, error) {
 	return &ldapUser, nil
 }
 
+// findLDAPUserGroups returns names and DNs of the groups found by the group
+// search filter for the user.
+func findLDAPUserGroups(l *ldap.Conn, userDN string) ([]string, error) {
+	searchRequest := ldap.NewSearchRequest(
+		util.Config().LdapGroupSearchDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		fmt.Sprintf(util.Config().LdapGroupSearchFilter, ldap.EscapeFilter(userDN)),
+		util.SplitLdapMapping(util.Config().LdapMappings.Group),
+		nil,
+	)
+
+	sr, err := l.Search(searchRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []string
+	for _, entry := range sr.Entries {
+		groups = append(groups, entry.DN)
+		if name := getLDAPAttributeValue(entry, util.Config().LdapMappings.Group); name != "" {
+			groups = append(groups, name)
+		}
+	}
+
+	return groups, nil
+}
+
+func isLDAPAdminGroupMember(groups []string) bool {
+	if util.Config().LdapAdminGroup == "" {
+		return false
+	}
+
+	for _, group := range groups {
+		if strings.EqualFold(group, util.Config().LdapAdminGroup) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // createSession creates session for passed user and stores session details
 // in cookies.
 func createSession(w http.ResponseWriter, r *http.Request, user db.User) {
@@ -156,9 +226,12 @@ func createSession(w http.ResponseWriter, r *http.Request, user db.User) {
 	}
 
 	http.SetCookie(w, &http.Cookie{
-		Name:  "semaphore",
-		Value: encoded,
-		Path:  "/",
+		Name:     "semaphore",
+		Value:    encoded,
+		Path:     "/",
+		MaxAge:   int(util.Config().GetSessionLifetime().Seconds()),
+		SameSite: util.Config().GetCookieSameSite(),
+		Secure:   util.Config().IsCookieSecure(),
 	})
 }
 
@@ -196,6 +269,11 @@ func loginByLDAP(store db.Store, ldapUser db.User) (user db.User, err error) {
 		return
 	}
 
+	if util.Config().LdapAdminGroup != "" && user.Admin != ldapUser.Admin {
+		user.Admin = ldapUser.Admin
+		err = store.UpdateUser(db.UserWithPwd{User: user})
+	}
+
 	return
 }
 
@@ -215,11 +293,11 @@ type loginMetadata struct {
 func login(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "GET" {
 		config := &loginMetadata{
-			OidcProviders:     make([]loginMetadataOidcProvider, len(util.Config.OidcProviders)),
-			LoginWithPassword: !util.Config.PasswordLoginDisable,
+			OidcProviders:     make([]loginMetadataOidcProvider, len(util.Config().OidcProviders)),
+			LoginWithPassword: !util.Config().PasswordLoginDisable,
 		}
 		i := 0
-		for k, v := range util.Config.OidcProviders {
+		for k, v := range util.Config().OidcProviders {
 			config.OidcProviders[i] = loginMetadataOidcProvider{
 				ID:    k,
 				Name:  v.DisplayName,
@@ -255,7 +333,7 @@ func login(w http.ResponseWriter, r *http.Request) {
 
 	var ldapUser *db.User
 
-	if util.Config.LdapEnable {
+	if util.Config().LdapEnable {
 		ldapUser, err = tryFindLDAPUser(login.Auth, login.Password)
 		if err != nil {
 			log.Warn(err.Error())
@@ -267,6 +345,10 @@ func login(w http.ResponseWriter, r *http.Request) {
 	var user db.User
 
 	if ldapUser == nil {
+		if util.Config().DisableLocalAuth {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
 		user, err = loginByPassword(helpers.Store(r), login.Auth, login.Password)
 	} else {
 		user, err = loginByLDAP(helpers.Store(r), *ldapUser)
@@ -294,17 +376,19 @@ func login(w http.ResponseWriter, r *http.Request) {
 
 func logout(w http.ResponseWriter, r *http.Request) {
 	http.SetCookie(w, &http.Cookie{
-		Name:    "semaphore",
-		Value:   "",
-		Expires: time.Now().Add(24 * 7 * time.Hour * -1),
-		Path:    "/",
+		Name:     "semaphore",
+		Value:    "",
+		Expires:  time.Now().Add(24 * 7 * time.Hour * -1),
+		Path:     "/",
+		SameSite: util.Config().GetCookieSameSite(),
+		Secure:   util.Config().IsCookieSecure(),
 	})
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
 func getOidcProvider(id string, ctx context.Context) (*oidc.Provider, *oauth2.Config, error) {
-	provider, ok := util.Config.OidcProviders[id]
+	provider, ok := util.Config().OidcProviders[id]
 	if !ok {
 		return nil, nil, fmt.Errorf("No such provider: %s", id)
 	}
@@ -333,7 +417,7 @@ func getOidcProvider(id string, ctx context.Context) (*oidc.Provider, *oauth2.Co
 		Scopes:       provider.Scopes,
 	}
 	if len(oauthConfig.RedirectURL) == 0 {
-		rurl, err := url.JoinPath(util.Config.WebHost, "api/auth/oidc", id, "redirect")
+		rurl, err := url.JoinPath(util.Config().GetApiHost(), "api/auth/oidc", id, "redirect")
 		if err != nil {
 			return nil, nil, err
 		}
@@ -351,7 +435,7 @@ func oidcLogin(w http.ResponseWriter, r *http.Request) {
 	_, oauth, err := getOidcProvider(pid, ctx)
 	if err != nil {
 		log.Error(err.Error())
-		http.Redirect(w, r, "/auth/login", http.StatusTemporaryRedirect)
+		http.Redirect(w, r, util.Config().GetWebPath("/auth/login"), http.StatusTemporaryRedirect)
 		return
 	}
 	state := generateStateOauthCookie(w)
@@ -365,7 +449,8 @@ func generateStateOauthCookie(w http.ResponseWriter) string {
 	b := make([]byte, 16)
 	rand.Read(b)
 	oauthState := base64.URLEncoding.EncodeToString(b)
-	cookie := http.Cookie{Name: "oauthstate", Value: oauthState, Expires: expiration}
+	// state cookie must be sent on redirect from the provider, so SameSite stays lax
+	cookie := http.Cookie{Name: "oauthstate", Value: oauthState, Expires: expiration, Secure: util.Config().IsCookieSecure()}
 	http.SetCookie(w, &cookie)
 
 	return oauthState
@@ -431,12 +516,12 @@ func oidcRedirect(w http.ResponseWriter, r *http.Request) {
 	oauthState, err := r.Cookie("oauthstate")
 	if err != nil {
 		log.Error(err.Error())
-		http.Redirect(w, r, "/auth/login", http.StatusTemporaryRedirect)
+		http.Redirect(w, r, util.Config().GetWebPath("/auth/login"), http.StatusTemporaryRedirect)
 		return
 	}
 
 	if r.FormValue("state") != oauthState.Value {
-		http.Redirect(w, r, "/auth/login", http.StatusTemporaryRedirect)
+		http.Redirect(w, r, util.Config().GetWebPath("/auth/login"), http.StatusTemporaryRedirect)
 		return
 	}
 
@@ -444,14 +529,14 @@ func oidcRedirect(w http.ResponseWriter, r *http.Request) {
 	_oidc, oauth, err := getOidcProvider(pid, ctx)
 	if err != nil {
 		log.Error(err.Error())
-		http.Redirect(w, r, "/auth/login", http.StatusTemporaryRedirect)
+		http.Redirect(w, r, util.Config().GetWebPath("/auth/login"), http.StatusTemporaryRedirect)
 		return
 	}
 
-	provider, ok := util.Config.OidcProviders[pid]
+	provider, ok := util.Config().OidcProviders[pid]
 	if !ok {
 		log.Error(fmt.Errorf("no such provider: %s", pid))
-		http.Redirect(w, r, "/auth/login", http.StatusTemporaryRedirect)
+		http.Redirect(w, r, util.Config().GetWebPath("/auth/login"), http.StatusTemporaryRedirect)
 		return
 	}
 
@@ -462,7 +547,7 @@ func oidcRedirect(w http.ResponseWriter, r *http.Request) {
 	oauth2Token, err := oauth.Exchange(ctx, code)
 	if err != nil {
 		log.Error(err.Error())
-		http.Redirect(w, r, "/auth/login", http.StatusTemporaryRedirect)
+		http.Redirect(w, r, util.Config().GetWebPath("/auth/login"), http.StatusTemporaryRedirect)
 		return
 	}
 
@@ -497,7 +582,7 @@ func oidcRedirect(w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		log.Error(err.Error())
-		http.Redirect(w, r, "/auth/login", http.StatusTemporaryRedirect)
+		http.Redirect(w, r, util.Config().GetWebPath("/auth/login"), http.StatusTemporaryRedirect)
 		return
 	}
 
@@ -512,18 +597,18 @@ func oidcRedirect(w http.ResponseWriter, r *http.Request) {
 		user, err = helpers.Store(r).CreateUserWithoutPassword(user)
 		if err != nil {
 			log.Error(err.Error())
-			http.Redirect(w, r, "/auth/login", http.StatusTemporaryRedirect)
+			http.Redirect(w, r, util.Config().GetWebPath("/auth/login"), http.StatusTemporaryRedirect)
 			return
 		}
 	}
 
 	if !user.External {
 		log.Error(fmt.Errorf("OIDC user '%s' conflicts with local user", user.Username))
-		http.Redirect(w, r, "/auth/login", http.StatusTemporaryRedirect)
+		http.Redirect(w, r, util.Config().GetWebPath("/auth/login"), http.StatusTemporaryRedirect)
 		return
 	}
 
 	createSession(w, r, user)
 
-	http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
+	http.Redirect(w, r, util.Config().GetWebPath("/"), http.StatusTemporaryRedirect)
 }
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ansible-semaphore/semaphore/util"
+)
+
+// loginAttempts are failed login attempts from one IP address
+// within the window started at start.
+type loginAttempts struct {
+	start    time.Time
+	failures int
+}
+
+// loginRateLimiter counts failed login attempts per IP address.
+type loginRateLimiter struct {
+	mutex    sync.Mutex
+	attempts map[string]*loginAttempts
+}
+
+var loginLimiter = &loginRateLimiter{
+	attempts: make(map[string]*loginAttempts),
+}
+
+// blocked reports whether the IP address exceeded limit of failed attempts.
+func (l *loginRateLimiter) blocked(ip string, limit int, window time.Duration, now time.Time) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	a, ok := l.attempts[ip]
+	if !ok {
+		return false
+	}
+
+	if now.Sub(a.start) > window {
+		delete(l.attempts, ip)
+		return false
+	}
+
+	return a.failures >= limit
+}
+
+// fail records failed login attempt from the IP address.
+func (l *loginRateLimiter) fail(ip string, window time.Duration, now time.Time) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// forget expired windows to keep the map small
+	for k, a := range l.attempts {
+		if now.Sub(a.start) > window {
+			delete(l.attempts, k)
+		}
+	}
+
+	a, ok := l.attempts[ip]
+	if !ok {
+		a = &loginAttempts{start: now}
+		l.attempts[ip] = a
+	}
+	a.failures++
+}
+
+// reset forgets failed attempts of the IP address after successful login.
+func (l *loginRateLimiter) reset(ip string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	delete(l.attempts, ip)
+}
+
+type peerAddrKey struct{}
+
+// WithPeerAddr returns a copy of the request which remembers its RemoteAddr
+// as address of the TCP peer. It must be applied before RemoteAddr is
+// replaced by the address from X-Forwarded-For header.
+func WithPeerAddr(r *http.Request) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), peerAddrKey{}, r.RemoteAddr))
+}
+
+// loginClientIP returns the IP address failed attempts are counted for.
+// It is the address of the TCP peer. The address from X-Forwarded-For
+// header is used only if the peer is one of TrustedProxies or the request
+// came over Unix socket, otherwise clients could bypass the limit
+// by sending a new header with each attempt.
+func loginClientIP(r *http.Request) string {
+	addr := r.RemoteAddr
+
+	if peer, ok := r.Context().Value(peerAddrKey{}).(string); ok {
+		conf := util.Config()
+		trusted := conf.SocketPath != "" || (len(conf.TrustedProxies) > 0 && conf.IsTrustedProxy(peer))
+		if !trusted {
+			addr = peer
+		}
+	}
+
+	ip, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		ip = addr
+	}
+
+	return ip
+}
+
+// loginStatusWriter remembers status code written by login handler.
+type loginStatusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *loginStatusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// loginRateLimitMiddleware rejects login requests with 429 status if
+// the client IP address has more than LoginRateLimit failed attempts
+// within LoginRateWindow seconds.
+func loginRateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := util.Config().LoginRateLimit
+		if limit == 0 || r.Method != "POST" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		window := time.Duration(util.Config().LoginRateWindow) * time.Second
+
+		ip := loginClientIP(r)
+
+		if loginLimiter.blocked(ip, limit, window, time.Now()) {
+			w.Header().Set("Retry-After", strconv.Itoa(util.Config().LoginRateWindow))
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		sw := &loginStatusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+
+		switch sw.status {
+		case http.StatusUnauthorized:
+			loginLimiter.fail(ip, window, time.Now())
+		case http.StatusNoContent:
+			loginLimiter.reset(ip)
+		}
+	})
+}
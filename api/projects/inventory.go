@@ -4,6 +4,7 @@ import (
 	log "github.com/Sirupsen/logrus"
 	"github.com/ansible-semaphore/semaphore/api/helpers"
 	"github.com/ansible-semaphore/semaphore/db"
+	"github.com/ansible-semaphore/semaphore/util"
 	"net/http"
 
 	"os"
@@ -82,8 +83,13 @@ func AddInventory(w http.ResponseWriter, r *http.Request) {
 	}
 
 	switch inventory.Type {
-	case db.InventoryStatic, db.InventoryStaticYaml, db.InventoryFile:
+	case db.InventoryStatic, db.InventoryStaticYaml:
 		break
+	case db.InventoryFile:
+		if !util.Config().IsAllowedUploadExtension(inventory.Inventory) {
+			writeNotAllowedExtension(w, inventory.Inventory)
+			return
+		}
 	default:
 		helpers.WriteJSON(w, http.StatusBadRequest, map[string]string{
 			"error": "Not supported inventory type",
@@ -139,6 +145,13 @@ func IsValidInventoryPath(path string) bool {
 	return !strings.HasPrefix(relPath, "..")
 }
 
+func writeNotAllowedExtension(w http.ResponseWriter, fileName string) {
+	helpers.WriteJSON(w, http.StatusBadRequest, map[string]string{
+		"error": "File extension is not allowed: " + fileName + ", allowed extensions: " +
+			strings.Join(util.Config().AllowedUploadExtensions, ", "),
+	})
+}
+
 // UpdateInventory writes updated values to an existing inventory item in the database
 func UpdateInventory(w http.ResponseWriter, r *http.Request) {
 	oldInventory := context.Get(r, "inventory").(db.Inventory)
@@ -171,6 +184,10 @@ func UpdateInventory(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
+		if !util.Config().IsAllowedUploadExtension(inventory.Inventory) {
+			writeNotAllowedExtension(w, inventory.Inventory)
+			return
+		}
 	default:
 		w.WriteHeader(http.StatusBadRequest)
 		return
@@ -192,7 +192,7 @@ func AddProject(w http.ResponseWriter, r *http.Request) {
 
 	user := context.Get(r, "user").(*db.User)
 
-	if !user.Admin && !util.Config.NonAdminCanCreateProject {
+	if !user.Admin && !util.Config().NonAdminCanCreateProject {
 		log.Warn(user.Username + " is not permitted to edit users")
 		w.WriteHeader(http.StatusUnauthorized)
 		return
@@ -1,11 +1,14 @@
 package api
 
 import (
+	"context"
 	"fmt"
+	log "github.com/Sirupsen/logrus"
 	"github.com/ansible-semaphore/semaphore/api/runners"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/ansible-semaphore/semaphore/api/helpers"
 	"github.com/ansible-semaphore/semaphore/api/projects"
@@ -46,7 +49,29 @@ func plainTextMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// healthCheckTimeout is time limit of the database ping of the health check.
+const healthCheckTimeout = 2 * time.Second
+
 func pongHandler(w http.ResponseWriter, r *http.Request) {
+	if util.Config() != nil && util.Config().HealthCheckDB {
+		store := helpers.Store(r)
+
+		var err error
+		db.StoreSession(store, util.RandString(12), func() {
+			ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+			defer cancel()
+			err = store.Ping(ctx)
+		})
+
+		if err != nil {
+			log.Error(err)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			//nolint: errcheck
+			w.Write([]byte("database is not available"))
+			return
+		}
+	}
+
 	//nolint: errcheck
 	w.Write([]byte("pong"))
 }
@@ -60,17 +85,23 @@ func notFoundHandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Println(r.Method, ":", r.URL.String(), "--> 404 Not Found")
 }
 
+// webHostPath returns path of WebHost which prefixes all routes. If WebRoot
+// is set, it is stripped from requests before routing, so routes aren't prefixed.
+func webHostPath() string {
+	if util.WebHostURL == nil || (util.Config() != nil && util.Config().WebRoot != "") {
+		return ""
+	}
+	return util.WebHostURL.Path
+}
+
 // Route declares all routes
 func Route() *mux.Router {
 	r := mux.NewRouter()
 	r.NotFoundHandler = http.HandlerFunc(servePublic)
 
-	webPath := "/"
-	if util.WebHostURL != nil {
-		webPath = util.WebHostURL.Path
-		if !strings.HasSuffix(webPath, "/") {
-			webPath += "/"
-		}
+	webPath := webHostPath()
+	if !strings.HasSuffix(webPath, "/") {
+		webPath += "/"
 	}
 
 	r.Use(mux.CORSMethodMiddleware(r))
@@ -84,7 +115,7 @@ func Route() *mux.Router {
 	publicAPIRouter.Use(StoreMiddleware, JSONMiddleware)
 
 	publicAPIRouter.HandleFunc("/runners", runners.RegisterRunner).Methods("POST")
-	publicAPIRouter.HandleFunc("/auth/login", login).Methods("GET", "POST")
+	publicAPIRouter.Handle("/auth/login", loginRateLimitMiddleware(http.HandlerFunc(login))).Methods("GET", "POST")
 	publicAPIRouter.HandleFunc("/auth/logout", logout).Methods("POST")
 	publicAPIRouter.HandleFunc("/auth/oidc/{provider}/login", oidcLogin).Methods("GET")
 	publicAPIRouter.HandleFunc("/auth/oidc/{provider}/redirect", oidcRedirect).Methods("GET")
@@ -311,8 +342,8 @@ func debugPrintRoutes(r *mux.Router) {
 // nolint: gocyclo
 func servePublic(w http.ResponseWriter, r *http.Request) {
 	webPath := "/"
-	if util.WebHostURL != nil {
-		webPath = util.WebHostURL.RequestURI()
+	if p := webHostPath(); p != "" {
+		webPath = p
 	}
 
 	path := r.URL.Path
@@ -341,8 +372,8 @@ func servePublic(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// replace base path
-	if util.WebHostURL != nil && path == "/index.html" {
-		baseURL := util.WebHostURL.String()
+	if (util.WebHostURL != nil || util.Config().WebRoot != "") && path == "/index.html" {
+		baseURL := util.Config().GetWebURL()
 		if !strings.HasSuffix(baseURL, "/") {
 			baseURL += "/"
 		}
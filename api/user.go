@@ -26,8 +26,8 @@ func getUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	user.User = *context.Get(r, "user").(*db.User)
-	user.CanCreateProject = user.Admin || util.Config.NonAdminCanCreateProject
-	user.Billing = util.Config.BillingEnabled
+	user.CanCreateProject = user.Admin || util.Config().NonAdminCanCreateProject
+	user.Billing = util.Config().BillingEnabled
 
 	helpers.WriteJSON(w, http.StatusOK, user)
 }
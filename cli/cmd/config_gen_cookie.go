@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ansible-semaphore/semaphore/util"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	configCmd.AddCommand(configGenCookieCmd)
+}
+
+var configGenCookieCmd = &cobra.Command{
+	Use:   "gen-cookie",
+	Short: "Print new cookie keys without loading configuration",
+	Run: func(cmd *cobra.Command, args []string) {
+		conf := util.ConfigType{}
+		conf.GenerateCookieSecrets()
+
+		bytes, err := json.MarshalIndent(map[string]string{
+			"cookie_hash":       conf.CookieHash,
+			"cookie_encryption": conf.CookieEncryption,
+		}, "", "\t")
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(string(bytes))
+	},
+}
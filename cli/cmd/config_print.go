@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/ansible-semaphore/semaphore/util"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+func init() {
+	configCmd.AddCommand(configPrintCmd)
+}
+
+var configPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Print effective configuration with all overrides applied, secrets are redacted",
+	Run: func(cmd *cobra.Command, args []string) {
+		util.ConfigSummaryLogging = false
+
+		conf, err := util.LoadEffectiveConfig(configPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		bytes, err := conf.ToJSONRedacted()
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(string(bytes))
+	},
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ansible-semaphore/semaphore/util"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	configCmd.AddCommand(configSchemaCmd)
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print JSON Schema of configuration file",
+	Run: func(cmd *cobra.Command, args []string) {
+		bytes, err := json.MarshalIndent(util.ConfigSchema(), "", "\t")
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(string(bytes))
+	},
+}
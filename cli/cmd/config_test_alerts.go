@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/ansible-semaphore/semaphore/services/tasks"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+func init() {
+	configCmd.AddCommand(configTestAlertsCmd)
+}
+
+var configTestAlertsCmd = &cobra.Command{
+	Use:   "test-alerts",
+	Short: "Send test alert through every enabled alert backend",
+	Run: func(cmd *cobra.Command, args []string) {
+		initConfig()
+
+		results := tasks.SendTestAlerts()
+		if len(results) == 0 {
+			fmt.Println("No alert backends are enabled")
+			return
+		}
+
+		ok := true
+		for _, res := range results {
+			if res.Err != nil {
+				fmt.Printf(" - %v: FAILED (%v)\n", res.Backend, res.Err)
+				ok = false
+			} else {
+				fmt.Printf(" - %v: OK\n", res.Backend)
+			}
+		}
+
+		if !ok {
+			os.Exit(1)
+		}
+	},
+}
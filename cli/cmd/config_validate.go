@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/ansible-semaphore/semaphore/util"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check configuration without starting the server",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := util.ValidateConfigFile(configPath)
+
+		if err == nil {
+			fmt.Println("Configuration is valid")
+			return
+		}
+
+		fmt.Println("Configuration is not valid:")
+
+		if errs, ok := err.(util.ConfigErrors); ok {
+			for _, e := range errs {
+				fmt.Println(" - " + e.Error())
+			}
+		} else {
+			fmt.Println(" - " + err.Error())
+		}
+
+		os.Exit(1)
+	},
+}
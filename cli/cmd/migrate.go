@@ -15,6 +15,6 @@ var migrateCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		store := createStore("migrate")
 		defer store.Close("migrate")
-		util.Config.PrintDbInfo()
+		util.Config().PrintDbInfo()
 	},
 }
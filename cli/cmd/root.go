@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	log "github.com/Sirupsen/logrus"
 	"github.com/ansible-semaphore/semaphore/api"
@@ -11,11 +12,14 @@ import (
 	"github.com/ansible-semaphore/semaphore/services/tasks"
 	"github.com/ansible-semaphore/semaphore/util"
 	"github.com/gorilla/context"
-	"github.com/gorilla/handlers"
 	"github.com/spf13/cobra"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 )
 
 var configPath string
@@ -33,7 +37,8 @@ Complete documentation is available at https://ansible-semaphore.com.`,
 }
 
 func Execute() {
-	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Configuration file path")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Configuration file path, - reads JSON config from stdin (defaults to SEMAPHORE_CONFIG_PATH or config.json in current directory)")
+	rootCmd.PersistentFlags().StringVar(&util.ConfigProfile, "profile", "", "Configuration profile, config.<profile>.json next to the configuration file is loaded on top of it (defaults to SEMAPHORE_CONFIG_PROFILE)")
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
@@ -41,28 +46,47 @@ func Execute() {
 }
 
 func runService() {
-	store := createStore("root")
+	initConfig()
+
+	if err := util.Config().CheckListenAddress(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	store := connectStore("root")
+
 	taskPool := tasks.CreateTaskPool(store)
 	schedulePool := schedules.CreateSchedulePool(store, &taskPool)
 
 	defer schedulePool.Destroy()
 
-	util.Config.PrintDbInfo()
-
-	port := util.Config.Port
+	util.Config().PrintDbInfo()
 
-	if !strings.HasPrefix(port, ":") {
-		port = ":" + port
+	fmt.Printf("Tmp Path (projects home) %v\n", util.Config().TmpPath)
+	if util.Config().CleanupTmpOnStart {
+		removed, err := util.Config().CleanupTmpPath(time.Now())
+		if err != nil {
+			log.Error(err)
+		}
+		fmt.Printf("Removed %v old entries from Tmp Path\n", removed)
+	}
+	if util.Config().RepoPath != "" {
+		fmt.Printf("Repository Path %v\n", util.Config().RepoPath)
 	}
-
-	fmt.Printf("Tmp Path (projects home) %v\n", util.Config.TmpPath)
 	fmt.Printf("Semaphore %v\n", util.Version)
-	fmt.Printf("Interface %v\n", util.Config.Interface)
-	fmt.Printf("Port %v\n", util.Config.Port)
+	if util.Config().SocketPath != "" {
+		fmt.Printf("Socket %v\n", util.Config().SocketPath)
+	} else if len(util.Config().ListenAddresses) > 0 {
+		fmt.Printf("Listen addresses %v\n", strings.Join(util.Config().ListenAddresses, ", "))
+	} else {
+		fmt.Printf("Interface %v\n", util.Config().Interface)
+		fmt.Printf("Port %v\n", util.Config().Port)
+	}
 
 	go sockets.StartWS()
 	go schedulePool.Run()
 	go taskPool.Run()
+	go reloadConfigOnSignal()
 
 	route := api.Route()
 
@@ -77,7 +101,15 @@ func runService() {
 
 	var router http.Handler = route
 
-	router = handlers.ProxyHeaders(router)
+	router = maxRequestBodySizeMiddleware(router)
+	router = corsMiddleware(router)
+	router = forceHTTPSMiddleware(router)
+	router = securityHeadersMiddleware(router)
+	router = allowedHostsMiddleware(router)
+	router = trustedProxyHeadersMiddleware(router)
+	if util.Config().WebRoot != "" {
+		router = http.StripPrefix(util.Config().WebRoot, router)
+	}
 	http.Handle("/", router)
 
 	fmt.Println("Server is running")
@@ -88,16 +120,114 @@ func runService() {
 		store.Close("root")
 	}
 
-	err := http.ListenAndServe(util.Config.Interface+port, cropTrailingSlashMiddleware(router))
+	var err error
+
+	if util.Config().SocketPath != "" {
+		err = listenAndServeUnix(util.Config().SocketPath, cropTrailingSlashMiddleware(router))
+	} else {
+		err = listenAndServeTCP(util.Config().GetListenAddresses(), cropTrailingSlashMiddleware(router))
+	}
 
 	if err != nil {
 		log.Panic(err)
 	}
 }
 
+// listenAndServeUnix serves HTTP on the Unix socket. Stale socket file
+// left by previous run is removed.
+func listenAndServeUnix(socketPath string, handler http.Handler) error {
+	if info, err := os.Stat(socketPath); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err = os.Remove(socketPath); err != nil {
+			return err
+		}
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return err
+	}
+
+	return newHTTPServer(handler).Serve(listener)
+}
+
+// listenAndServeTCP serves HTTP on all the addresses. All of them are bound
+// before serving, so the server doesn't start partially. It returns when
+// serving on any of the addresses fails.
+func listenAndServeTCP(addresses []string, handler http.Handler) error {
+	var listeners []net.Listener
+
+	for _, address := range addresses {
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			for _, l := range listeners {
+				_ = l.Close()
+			}
+			return err
+		}
+		listeners = append(listeners, listener)
+	}
+
+	errs := make(chan error, len(listeners))
+	for _, listener := range listeners {
+		go func(listener net.Listener) {
+			errs <- newHTTPServer(handler).Serve(listener)
+		}(listener)
+	}
+
+	return <-errs
+}
+
+// newHTTPServer creates HTTP server with timeouts from the config.
+func newHTTPServer(handler http.Handler) *http.Server {
+	return &http.Server{
+		Handler:      handler,
+		ReadTimeout:  time.Duration(util.Config().HTTPReadTimeout) * time.Second,
+		WriteTimeout: time.Duration(util.Config().HTTPWriteTimeout) * time.Second,
+		IdleTimeout:  time.Duration(util.Config().HTTPIdleTimeout) * time.Second,
+	}
+}
+
+// reloadConfigOnSignal reloads the config each time the process receives SIGHUP.
+func reloadConfigOnSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	for range signals {
+		if err := util.ReloadConfig(configPath); err != nil {
+			log.Error("Can't reload config: " + err.Error())
+			continue
+		}
+		log.Info("Config reloaded")
+	}
+}
+
+// initConfig loads the config and exits if it can't be loaded.
+func initConfig() {
+	err := util.ConfigInit(configPath)
+
+	switch {
+	case err == nil:
+		return
+	case errors.Is(err, util.ErrConfigNotFound):
+		fmt.Println("Cannot Find configuration! Use --config parameter to point to a JSON file generated by `semaphore setup`.")
+	case errors.Is(err, util.ErrConfigDecode):
+		fmt.Println("Could not decode configuration!")
+		fmt.Println(err)
+	default:
+		fmt.Println(err)
+	}
+
+	os.Exit(1)
+}
+
 func createStore(token string) db.Store {
-	util.ConfigInit(configPath)
+	initConfig()
+
+	return connectStore(token)
+}
 
+// connectStore connects to the database of the loaded config and migrates it.
+func connectStore(token string) db.Store {
 	store := factory.CreateStore()
 
 	store.Connect(token)
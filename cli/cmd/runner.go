@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"github.com/ansible-semaphore/semaphore/services/runners"
-	"github.com/ansible-semaphore/semaphore/util"
 	"github.com/spf13/cobra"
 )
 
@@ -11,7 +10,7 @@ func init() {
 }
 
 func runRunner() {
-	util.ConfigInit(configPath)
+	initConfig()
 
 	taskPool := runners.JobPool{}
 
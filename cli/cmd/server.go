@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"github.com/ansible-semaphore/semaphore/api"
+	"github.com/ansible-semaphore/semaphore/util"
+	"github.com/gorilla/handlers"
 	"github.com/spf13/cobra"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -27,3 +31,119 @@ func cropTrailingSlashMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// trustedProxyHeadersMiddleware applies X-Forwarded-* headers only to
+// requests from trusted proxies. Requests received over Unix socket
+// always come from local proxy. The address of the TCP peer is kept
+// in the request context for the login rate limiter.
+func trustedProxyHeadersMiddleware(next http.Handler) http.Handler {
+	proxied := handlers.ProxyHeaders(next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = api.WithPeerAddr(r)
+		if util.Config().SocketPath != "" || util.Config().IsTrustedProxy(r.RemoteAddr) {
+			proxied.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// forceHTTPSMiddleware redirects plain HTTP requests to HTTPS if ForceHTTPS
+// is enabled. It must be placed after trustedProxyHeadersMiddleware, which
+// sets scheme of the request from X-Forwarded-Proto header.
+func forceHTTPSMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !util.Config().ForceHTTPS || r.TLS != nil || r.URL.Scheme == "https" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		host := r.Host
+		if util.WebHostURL != nil && util.WebHostURL.Scheme == "https" {
+			host = util.WebHostURL.Host
+		}
+
+		// RequestURI isn't changed by http.StripPrefix, it contains WebRoot
+		http.Redirect(w, r, "https://"+host+r.RequestURI, http.StatusMovedPermanently)
+	})
+}
+
+// securityHeadersMiddleware sets security headers enabled in the config.
+// It must be placed after trustedProxyHeadersMiddleware, HSTS header
+// is sent only over HTTPS.
+func securityHeadersMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if util.Config().EnableHSTS && (r.TLS != nil || r.URL.Scheme == "https") {
+			w.Header().Set("Strict-Transport-Security", "max-age="+strconv.Itoa(util.Config().HSTSMaxAge)+"; includeSubDomains")
+		}
+		if util.Config().ContentTypeNoSniff {
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+		}
+		if util.Config().FrameOptions != "" {
+			w.Header().Set("X-Frame-Options", strings.ToUpper(util.Config().FrameOptions))
+		}
+		if util.Config().ContentSecurityPolicy != "" {
+			w.Header().Set("Content-Security-Policy", util.Config().ContentSecurityPolicy)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowedHostsMiddleware rejects requests with Host header which isn't
+// listed in AllowedHosts.
+func allowedHostsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !util.Config().IsAllowedHost(r.Host) {
+			http.Error(w, "Host is not allowed", http.StatusBadRequest)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// corsMiddleware allows cross-origin requests from AllowedOrigins and
+// answers preflight requests. Credentials are allowed only for the origins
+// listed explicitly.
+func corsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowedOrigin := util.Config().GetAllowedOrigin(r.Header.Get("Origin"))
+		if allowedOrigin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
+		w.Header().Add("Vary", "Origin")
+		if allowedOrigin != "*" {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.Header().Set("Access-Control-Max-Age", "86400")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// maxRequestBodySizeMiddleware limits size of request body by MaxRequestBodySize.
+// Requests with larger Content-Length are rejected at once, reading
+// of body without Content-Length fails after the limit.
+func maxRequestBodySizeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := int64(util.Config().MaxRequestBodySize)
+		if limit > 0 {
+			if r.ContentLength > limit {
+				http.Error(w, "Request body is too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -12,7 +12,12 @@ import (
 	"strings"
 )
 
+var setupArgs struct {
+	dryRun bool
+}
+
 func init() {
+	setupCmd.PersistentFlags().BoolVar(&setupArgs.dryRun, "dry-run", false, "Print generated config instead of writing it and setting up database")
 	rootCmd.AddCommand(setupCmd)
 }
 
@@ -27,12 +32,24 @@ var setupCmd = &cobra.Command{
 // nolint: gocyclo
 func doSetup() int {
 	var config *util.ConfigType
-	config = &util.ConfigType{}
+	config = &util.ConfigType{
+		MaxParallelTasks: util.DefaultMaxParallelTasks,
+		AlertsEnabled:    true,
+	}
 	config.GenerateSecrets()
 	setup.InteractiveSetup(config)
 
+	if setupArgs.dryRun {
+		bytes, err := config.ToJSON()
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(string(bytes))
+		return 0
+	}
+
 	configPath := setup.SaveConfig(config)
-	util.Config = config
+	util.SetConfig(config)
 
 	fmt.Println(" Pinging db..")
 
@@ -81,7 +98,7 @@ func doSetup() int {
 }
 
 func readNewline(pre string, stdin *bufio.Reader) string {
-	fmt.Print(pre)
+	fmt.Fprint(os.Stderr, pre)
 
 	str, err := stdin.ReadString('\n')
 	util.LogWarning(err)
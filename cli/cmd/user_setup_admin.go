@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/ansible-semaphore/semaphore/db"
+	"github.com/ansible-semaphore/semaphore/util"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+func init() {
+	userCmd.AddCommand(userSetupAdminCmd)
+}
+
+var userSetupAdminCmd = &cobra.Command{
+	Use:   "setup-admin",
+	Short: "Create admin user from admin_user, admin_password, admin_email and admin_name settings if it doesn't exist",
+	Run: func(cmd *cobra.Command, args []string) {
+		store := createStore("")
+		defer store.Close("")
+
+		if util.Config().AdminUser == "" {
+			fmt.Println("Setting admin_user (SEMAPHORE_ADMIN_USER) required")
+			os.Exit(1)
+		}
+
+		_, err := store.GetUserByLoginOrEmail(util.Config().AdminUser, util.Config().AdminEmail)
+
+		if err == nil {
+			fmt.Printf("User %s already exists\n", util.Config().AdminUser)
+			return
+		}
+
+		if err != db.ErrNotFound {
+			panic(err)
+		}
+
+		name := util.Config().AdminName
+		if name == "" {
+			name = util.Config().AdminUser
+		}
+
+		if _, err = store.CreateUser(db.UserWithPwd{
+			Pwd: util.Config().AdminPassword,
+			User: db.User{
+				Name:     name,
+				Username: util.Config().AdminUser,
+				Email:    util.Config().AdminEmail,
+				Admin:    true,
+			},
+		}); err != nil {
+			panic(err)
+		}
+
+		fmt.Printf("Admin user %s <%s> added!\n", util.Config().AdminUser, util.Config().AdminEmail)
+	},
+}
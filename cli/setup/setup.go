@@ -23,12 +23,13 @@ Hello! You will now be guided through a setup to:
 `
 
 func InteractiveSetup(conf *util.ConfigType) {
-	fmt.Print(interactiveSetupBlurb)
+	fmt.Fprint(os.Stderr, interactiveSetupBlurb)
 
 	dbPrompt := `What database to use:
    1 - MySQL
    2 - BoltDB
    3 - PostgreSQL
+   4 - SQLite
 `
 
 	var db int
@@ -44,6 +45,9 @@ func InteractiveSetup(conf *util.ConfigType) {
 	case 3:
 		conf.Dialect = util.DbDriverPostgres
 		scanPostgres(conf)
+	case 4:
+		conf.Dialect = util.DbDriverSQLite
+		scanSQLite(conf)
 	}
 
 	defaultPlaybookPath := filepath.Join(os.TempDir(), "semaphore")
@@ -57,12 +61,17 @@ func InteractiveSetup(conf *util.ConfigType) {
 		askValue("Mail server host", "localhost", &conf.EmailHost)
 		askValue("Mail server port", "25", &conf.EmailPort)
 		askValue("Mail sender address", "semaphore@localhost", &conf.EmailSender)
+		askValue("Mail server username (optional)", "", &conf.EmailUsername)
+		if conf.EmailUsername != "" {
+			askValue("Mail server password", "", &conf.EmailPassword)
+		}
+		askValue("Mail server connection security (none/starttls/tls)", util.MailSecurityNone, &conf.EmailSecurity)
 	}
 
 	askConfirmation("Enable telegram alerts?", false, &conf.TelegramAlert)
 	if conf.TelegramAlert {
 		askValue("Telegram bot token (you can get it from @BotFather)", "", &conf.TelegramToken)
-		askValue("Telegram chat ID", "", &conf.TelegramChat)
+		askValue("Telegram chat ID (comma-separated for several chats)", "", &conf.TelegramChat)
 	}
 
 	askConfirmation("Enable slack alerts?", false, &conf.SlackAlert)
@@ -70,10 +79,43 @@ func InteractiveSetup(conf *util.ConfigType) {
 		askValue("Slack Webhook URL", "", &conf.SlackUrl)
 	}
 
+	askConfirmation("Enable Microsoft Teams alerts?", false, &conf.TeamsAlert)
+	if conf.TeamsAlert {
+		askValue("Microsoft Teams Webhook URL", "", &conf.TeamsUrl)
+	}
+
+	askConfirmation("Enable Discord alerts?", false, &conf.DiscordAlert)
+	if conf.DiscordAlert {
+		askValue("Discord Webhook URL", "", &conf.DiscordUrl)
+	}
+
+	askConfirmation("Enable Gotify alerts?", false, &conf.GotifyAlert)
+	if conf.GotifyAlert {
+		askValue("Gotify server URL", "", &conf.GotifyUrl)
+		askValue("Gotify application token", "", &conf.GotifyToken)
+	}
+
+	askConfirmation("Enable generic webhook alerts?", false, &conf.WebhookAlert)
+	if conf.WebhookAlert {
+		askValue("Webhook URL", "", &conf.WebhookUrl)
+	}
+
+	askConfirmation("Enable PagerDuty alerts?", false, &conf.PagerDutyAlert)
+	if conf.PagerDutyAlert {
+		askValue("PagerDuty Events API v2 routing key", "", &conf.PagerDutyRoutingKey)
+	}
+
 	askConfirmation("Enable LDAP authentication?", false, &conf.LdapEnable)
 	if conf.LdapEnable {
 		askValue("LDAP server host", "localhost:389", &conf.LdapServer)
 		askConfirmation("Enable LDAP TLS connection", false, &conf.LdapNeedTLS)
+		if conf.LdapNeedTLS {
+			askValue("LDAP CA certificate file (optional, server certificate is not verified if empty)", "", &conf.LdapCACert)
+			askValue("LDAP client certificate file (optional)", "", &conf.LdapClientCert)
+			if conf.LdapClientCert != "" {
+				askValue("LDAP client key file", "", &conf.LdapClientKey)
+			}
+		}
 		askValue("LDAP DN for bind", "cn=user,ou=users,dc=example", &conf.LdapBindDN)
 		askValue("Password for LDAP bind user", "pa55w0rd", &conf.LdapBindPassword)
 		askValue("LDAP DN for user search", "ou=users,dc=example", &conf.LdapSearchDN)
@@ -82,6 +124,11 @@ func InteractiveSetup(conf *util.ConfigType) {
 		askValue("LDAP mapping for username field", "uid", &conf.LdapMappings.UID)
 		askValue("LDAP mapping for full name field", "cn", &conf.LdapMappings.CN)
 		askValue("LDAP mapping for email field", "mail", &conf.LdapMappings.Mail)
+		askValue("LDAP DN for group search (optional, restricts login to group members)", "", &conf.LdapGroupSearchDN)
+		if conf.LdapGroupSearchDN != "" {
+			askValue("LDAP group search filter", `(member=%s)`, &conf.LdapGroupSearchFilter)
+			askValue("LDAP admin group (optional)", "", &conf.LdapAdminGroup)
+		}
 	}
 }
 
@@ -94,6 +141,15 @@ func scanBoltDb(conf *util.ConfigType) {
 	askValue("db filename", defaultBoltDBPath, &conf.BoltDb.Hostname)
 }
 
+func scanSQLite(conf *util.ConfigType) {
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		workingDirectory = os.TempDir()
+	}
+	defaultSQLitePath := filepath.Join(workingDirectory, "database.sqlite")
+	askValue("db filename", defaultSQLitePath, &conf.SQLite.Hostname)
+}
+
 func scanMySQL(conf *util.ConfigType) {
 	askValue("db Hostname", "127.0.0.1:3306", &conf.MySQL.Hostname)
 	askValue("db User", "root", &conf.MySQL.Username)
@@ -144,13 +200,26 @@ func SaveConfig(config *util.ConfigType) (configPath string) {
 		log.Panic("Could not create config directory: " + err.Error())
 	}
 
-	// Marshal config to json
-	bytes, err := config.ToJSON()
+	configFormat := "json"
+	askValue("Config file format (json/yaml)", configFormat, &configFormat)
+
+	var bytes []byte
+	var configFileName string
+
+	switch strings.ToLower(configFormat) {
+	case "yaml", "yml":
+		bytes, err = config.ToYAML()
+		configFileName = "config.yaml"
+	default:
+		bytes, err = config.ToJSON()
+		configFileName = "config.json"
+	}
+
 	if err != nil {
 		panic(err)
 	}
 
-	configPath = filepath.Join(configDirectory, "config.json")
+	configPath = filepath.Join(configDirectory, configFileName)
 	if err = ioutil.WriteFile(configPath, bytes, 0644); err != nil {
 		panic(err)
 	}
@@ -174,18 +243,18 @@ func AskConfigConfirmation(config *util.ConfigType) bool {
 
 func askValue(prompt string, defaultValue string, item interface{}) {
 	// Print prompt with optional default value
-	fmt.Print(prompt)
+	fmt.Fprint(os.Stderr, prompt)
 	if len(defaultValue) != 0 {
-		fmt.Print(" (default " + defaultValue + ")")
+		fmt.Fprint(os.Stderr, " (default "+defaultValue+")")
 	}
-	fmt.Print(": ")
+	fmt.Fprint(os.Stderr, ": ")
 
 	_, _ = fmt.Sscanln(defaultValue, item)
 
 	scanErrorChecker(fmt.Scanln(item))
 
 	// Empty line after prompt
-	fmt.Println("")
+	fmt.Fprintln(os.Stderr, "")
 }
 
 func askConfirmation(prompt string, defaultValue bool, item *bool) {
@@ -194,7 +263,7 @@ func askConfirmation(prompt string, defaultValue bool, item *bool) {
 		defString = "no"
 	}
 
-	fmt.Print(prompt + " (yes/no) (default " + defString + "): ")
+	fmt.Fprint(os.Stderr, prompt+" (yes/no) (default "+defString+"): ")
 
 	var answer string
 
@@ -210,5 +279,5 @@ func askConfirmation(prompt string, defaultValue bool, item *bool) {
 	}
 
 	// Empty line after prompt
-	fmt.Println("")
+	fmt.Fprintln(os.Stderr, "")
 }
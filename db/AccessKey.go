@@ -85,7 +85,7 @@ func (key AccessKeyInstallation) Destroy() error {
 
 // GetPath returns the location of the access key once written to disk
 func (key AccessKeyInstallation) GetPath() string {
-	return util.Config.TmpPath + "/access_key_" + strconv.FormatInt(key.InstallationKey, 10)
+	return util.Config().TmpPath + "/access_key_" + strconv.FormatInt(key.InstallationKey, 10)
 }
 
 func (key *AccessKey) startSshAgent(logger lib.Logger) (lib.SshAgent, error) {
@@ -98,7 +98,7 @@ func (key *AccessKey) startSshAgent(logger lib.Logger) (lib.SshAgent, error) {
 				Passphrase: []byte(key.SshKey.Passphrase),
 			},
 		},
-		SocketFile: path.Join(util.Config.TmpPath, fmt.Sprintf("ssh-agent-%d-%d.sock", key.ID, time.Now().Unix())),
+		SocketFile: path.Join(util.Config().TmpPath, fmt.Sprintf("ssh-agent-%d-%d.sock", key.ID, time.Now().Unix())),
 	}
 
 	return sshAgent, sshAgent.Listen()
@@ -225,7 +225,7 @@ func (key *AccessKey) SerializeSecret() error {
 		return fmt.Errorf("invalid access token type")
 	}
 
-	encryptionString := util.Config.AccessKeyEncryption
+	encryptionString := util.Config().AccessKeyEncryption
 
 	if encryptionString == "" {
 		secret := base64.StdEncoding.EncodeToString(plaintext)
@@ -279,7 +279,7 @@ func (key *AccessKey) unmarshalAppropriateField(secret []byte) (err error) {
 }
 
 func (key *AccessKey) DeserializeSecret() error {
-	return key.DeserializeSecret2(util.Config.AccessKeyEncryption)
+	return key.DeserializeSecret2(util.Config().AccessKeyEncryption)
 }
 
 func (key *AccessKey) DeserializeSecret2(encryptionString string) error {
@@ -14,7 +14,7 @@ func TestSetSecret(t *testing.T) {
 		},
 	}
 
-	util.Config = &util.ConfigType{}
+	util.SetConfig(&util.ConfigType{})
 	err := accessKey.SerializeSecret()
 
 	if err != nil {
@@ -37,7 +37,7 @@ func TestGetSecret(t *testing.T) {
 	"passphrase": "123456",
 	"private_key": "qerphqeruqoweurqwerqqeuiqwpavqr"
 }`))
-	util.Config = &util.ConfigType{}
+	util.SetConfig(&util.ConfigType{})
 
 	accessKey := AccessKey{
 		Secret: &secret,
@@ -67,9 +67,9 @@ func TestSetGetSecretWithEncryption(t *testing.T) {
 		},
 	}
 
-	util.Config = &util.ConfigType{
+	util.SetConfig(&util.ConfigType{
 		AccessKeyEncryption: "hHYgPrhQTZYm7UFTvcdNfKJMB3wtAXtJENUButH+DmM=",
-	}
+	})
 
 	err := accessKey.SerializeSecret()
 
@@ -32,7 +32,7 @@ type Repository struct {
 }
 
 func (r Repository) ClearCache() error {
-	dir, err := os.Open(util.Config.TmpPath)
+	dir, err := os.Open(util.Config().GetRepoPath())
 	if err != nil {
 		return err
 	}
@@ -47,7 +47,7 @@ func (r Repository) ClearCache() error {
 			continue
 		}
 		if strings.HasPrefix(f.Name(), r.getDirNamePrefix()) {
-			err = os.RemoveAll(path.Join(util.Config.TmpPath, f.Name()))
+			err = os.RemoveAll(path.Join(util.Config().GetRepoPath(), f.Name()))
 			if err != nil {
 				return err
 			}
@@ -69,7 +69,7 @@ func (r Repository) GetFullPath(templateID int) string {
 	if r.GetType() == RepositoryLocal {
 		return r.GetGitURL()
 	}
-	return path.Join(util.Config.TmpPath, r.GetDirName(templateID))
+	return path.Join(util.Config().GetRepoPath(), r.GetDirName(templateID))
 }
 
 func (r Repository) GetGitURL() string {
@@ -17,10 +17,10 @@ func TestRepository_GetSchema(t *testing.T) {
 }
 
 func TestRepository_ClearCache(t *testing.T) {
-	util.Config = &util.ConfigType{
+	util.SetConfig(&util.ConfigType{
 		TmpPath: path.Join(os.TempDir(), util.RandString(rand.Intn(10-4)+4)),
-	}
-	repoDir := path.Join(util.Config.TmpPath, "repository_123_55")
+	})
+	repoDir := path.Join(util.Config().TmpPath, "repository_123_55")
 	err := os.MkdirAll(repoDir, 0755)
 	if err != nil {
 		t.Fatal(err)
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	log "github.com/Sirupsen/logrus"
@@ -88,6 +89,9 @@ type Store interface {
 	// For BoltDB we should reconnect for each request because BoltDB support only one connection at time.
 	PermanentConnection() bool
 
+	// Ping checks that the database is available.
+	Ping(ctx context.Context) error
+
 	// IsInitialized indicates is database already initialized, or it is empty.
 	// The method is useful for creating required entities in database during first run.
 	IsInitialized() (bool, error)
@@ -194,6 +198,17 @@ type Store interface {
 	DeleteTaskWithOutputs(projectID int, taskID int) error
 	GetTaskOutputs(projectID int, taskID int) ([]TaskOutput, error)
 	CreateTaskOutput(output TaskOutput) (TaskOutput, error)
+	// CreateTaskOutputs writes several output lines at once.
+	CreateTaskOutputs(outputs []TaskOutput) error
+	// DeleteFinishedTasksBefore deletes finished tasks created before
+	// the passed time with their output.
+	DeleteFinishedTasksBefore(before time.Time) error
+	// TrimTaskOutputs deletes the oldest output lines of each task
+	// which has more than maxLines lines.
+	TrimTaskOutputs(maxLines int) error
+	// TrimTemplateTasks deletes finished tasks of each template except
+	// the maxTasks most recent tasks.
+	TrimTemplateTasks(maxTasks int) error
 
 	GetView(projectID int, viewID int) (View, error)
 	GetViews(projectID int) ([]View, error)
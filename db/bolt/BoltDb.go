@@ -2,6 +2,7 @@ package bolt
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/ansible-semaphore/semaphore/db"
@@ -93,7 +94,7 @@ func (d *BoltDb) Connect(token string) {
 
 	var filename string
 	if d.Filename == "" {
-		config, err := util.Config.GetDBConfig()
+		config, err := util.Config().GetDBConfig()
 		if err != nil {
 			panic(err)
 		}
@@ -143,6 +144,17 @@ func (d *BoltDb) PermanentConnection() bool {
 	return false
 }
 
+// Ping checks that the database file is open and readable,
+// BoltDB operations can't be cancelled, so ctx is ignored.
+func (d *BoltDb) Ping(ctx context.Context) error {
+	if d.db == nil {
+		return fmt.Errorf("database is not opened")
+	}
+	return d.db.View(func(tx *bbolt.Tx) error {
+		return nil
+	})
+}
+
 func (d *BoltDb) IsInitialized() (initialized bool, err error) {
 	err = d.db.View(func(tx *bbolt.Tx) error {
 		k, _ := tx.Cursor().First()
@@ -460,89 +472,94 @@ func (d *BoltDb) updateObject(bucketID int, props db.ObjectProps, object interfa
 	})
 }
 
-func (d *BoltDb) createObject(bucketID int, props db.ObjectProps, object interface{}) (interface{}, error) {
-	err := d.db.Update(func(tx *bbolt.Tx) error {
-		b, err := tx.CreateBucketIfNotExists(makeBucketId(props, bucketID))
+func (d *BoltDb) createObjectTx(tx *bbolt.Tx, bucketID int, props db.ObjectProps, object interface{}) (interface{}, error) {
+	b, err := tx.CreateBucketIfNotExists(makeBucketId(props, bucketID))
 
-		if err != nil {
-			return err
-		}
+	if err != nil {
+		return object, err
+	}
 
-		objPtr := reflect.ValueOf(&object).Elem()
+	objPtr := reflect.ValueOf(&object).Elem()
 
-		tmpObj := reflect.New(objPtr.Elem().Type()).Elem()
-		tmpObj.Set(objPtr.Elem())
+	tmpObj := reflect.New(objPtr.Elem().Type()).Elem()
+	tmpObj.Set(objPtr.Elem())
 
-		var objID objectID
+	var objID objectID
 
-		if props.PrimaryColumnName != "" {
-			idFieldName, err2 := getFieldNameByTagSuffix(reflect.TypeOf(object), "db", props.PrimaryColumnName)
+	if props.PrimaryColumnName != "" {
+		idFieldName, err2 := getFieldNameByTagSuffix(reflect.TypeOf(object), "db", props.PrimaryColumnName)
 
-			if err2 != nil {
-				return err2
-			}
+		if err2 != nil {
+			return object, err2
+		}
 
-			idValue := tmpObj.FieldByName(idFieldName)
-
-			switch idValue.Kind() {
-			case reflect.Int,
-				reflect.Int8,
-				reflect.Int16,
-				reflect.Int32,
-				reflect.Int64,
-				reflect.Uint,
-				reflect.Uint8,
-				reflect.Uint16,
-				reflect.Uint32,
-				reflect.Uint64:
-				if idValue.Int() == 0 {
-					id, err3 := b.NextSequence()
-					if err3 != nil {
-						return err3
-					}
-					if props.SortInverted {
-						id = MaxID - id
-					}
-					idValue.SetInt(int64(id))
-				}
+		idValue := tmpObj.FieldByName(idFieldName)
 
-				objID = intObjectID(idValue.Int())
-			case reflect.String:
-				if idValue.String() == "" {
-					return fmt.Errorf("object ID can not be empty string")
-				}
-				objID = strObjectID(idValue.String())
-			case reflect.Invalid:
+		switch idValue.Kind() {
+		case reflect.Int,
+			reflect.Int8,
+			reflect.Int16,
+			reflect.Int32,
+			reflect.Int64,
+			reflect.Uint,
+			reflect.Uint8,
+			reflect.Uint16,
+			reflect.Uint32,
+			reflect.Uint64:
+			if idValue.Int() == 0 {
 				id, err3 := b.NextSequence()
 				if err3 != nil {
-					return err3
+					return object, err3
 				}
-				objID = intObjectID(id)
-			default:
-				return fmt.Errorf("unsupported ID type")
+				if props.SortInverted {
+					id = MaxID - id
+				}
+				idValue.SetInt(int64(id))
 			}
-		} else {
-			id, err2 := b.NextSequence()
-			if err2 != nil {
-				return err2
+
+			objID = intObjectID(idValue.Int())
+		case reflect.String:
+			if idValue.String() == "" {
+				return object, fmt.Errorf("object ID can not be empty string")
 			}
-			if props.SortInverted {
-				id = MaxID - id
+			objID = strObjectID(idValue.String())
+		case reflect.Invalid:
+			id, err3 := b.NextSequence()
+			if err3 != nil {
+				return object, err3
 			}
 			objID = intObjectID(id)
+		default:
+			return object, fmt.Errorf("unsupported ID type")
 		}
-
-		if objID == nil {
-			return fmt.Errorf("object ID can not be nil")
+	} else {
+		id, err2 := b.NextSequence()
+		if err2 != nil {
+			return object, err2
 		}
-
-		objPtr.Set(tmpObj)
-		str, err := marshalObject(object)
-		if err != nil {
-			return err
+		if props.SortInverted {
+			id = MaxID - id
 		}
+		objID = intObjectID(id)
+	}
+
+	if objID == nil {
+		return object, fmt.Errorf("object ID can not be nil")
+	}
+
+	objPtr.Set(tmpObj)
+	str, err := marshalObject(object)
+	if err != nil {
+		return object, err
+	}
+
+	return object, b.Put(objID.ToBytes(), str)
+}
 
-		return b.Put(objID.ToBytes(), str)
+func (d *BoltDb) createObject(bucketID int, props db.ObjectProps, object interface{}) (interface{}, error) {
+	err := d.db.Update(func(tx *bbolt.Tx) (err error) {
+		object, err = d.createObjectTx(tx, bucketID, props, object)
+		return
 	})
 
 	return object, err
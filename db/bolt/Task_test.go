@@ -2,7 +2,9 @@ package bolt
 
 import (
 	"github.com/ansible-semaphore/semaphore/db"
+	"github.com/ansible-semaphore/semaphore/lib"
 	"testing"
+	"time"
 )
 
 func TestTask_GetVersion(t *testing.T) {
@@ -89,3 +91,128 @@ func TestTask_GetVersion(t *testing.T) {
 		return
 	}
 }
+
+func TestTask_DeleteFinishedTasksBefore(t *testing.T) {
+	store := CreateTestStore()
+
+	finished, err := store.CreateTask(db.Task{ProjectID: 1, Status: lib.TaskSuccessStatus})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	running, err := store.CreateTask(db.Task{ProjectID: 1, Status: lib.TaskRunningStatus})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = store.CreateTaskOutput(db.TaskOutput{TaskID: finished.ID, Output: "done"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.DeleteFinishedTasksBefore(time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = store.GetTask(1, finished.ID); err != db.ErrNotFound {
+		t.Fatal("finished task must be deleted")
+	}
+
+	if _, err = store.GetTask(1, running.ID); err != nil {
+		t.Fatal("running task must be kept")
+	}
+}
+
+func TestTask_TrimTaskOutputs(t *testing.T) {
+	store := CreateTestStore()
+
+	task, err := store.CreateTask(db.Task{ProjectID: 1, Status: lib.TaskSuccessStatus})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{"1", "2", "3", "4"} {
+		_, err = store.CreateTaskOutput(db.TaskOutput{TaskID: task.ID, Output: line})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = store.TrimTaskOutputs(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outputs, err := store.GetTaskOutputs(1, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(outputs) != 2 || outputs[0].Output != "3" || outputs[1].Output != "4" {
+		t.Fatalf("unexpected output after trimming: %v", outputs)
+	}
+}
+
+func TestTask_CreateTaskOutputs(t *testing.T) {
+	store := CreateTestStore()
+
+	task, err := store.CreateTask(db.Task{ProjectID: 1, Status: lib.TaskRunningStatus})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.CreateTaskOutputs([]db.TaskOutput{
+		{TaskID: task.ID, Output: "1"},
+		{TaskID: task.ID, Output: "2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outputs, err := store.GetTaskOutputs(1, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(outputs) != 2 || outputs[0].Output != "1" || outputs[1].Output != "2" {
+		t.Fatalf("unexpected output: %v", outputs)
+	}
+}
+
+func TestTask_TrimTemplateTasks(t *testing.T) {
+	store := CreateTestStore()
+
+	var tasks []db.Task
+	for _, status := range []lib.TaskStatus{lib.TaskSuccessStatus, lib.TaskFailStatus, lib.TaskSuccessStatus, lib.TaskSuccessStatus} {
+		task, err := store.CreateTask(db.Task{ProjectID: 1, TemplateID: 1, Status: status})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	other, err := store.CreateTask(db.Task{ProjectID: 1, TemplateID: 2, Status: lib.TaskSuccessStatus})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.TrimTemplateTasks(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, task := range tasks {
+		_, err = store.GetTask(1, task.ID)
+		if i < 2 && err != db.ErrNotFound {
+			t.Errorf("old task %d must be deleted", task.ID)
+		}
+		if i >= 2 && err != nil {
+			t.Errorf("recent task %d must be kept", task.ID)
+		}
+	}
+
+	if _, err = store.GetTask(1, other.ID); err != nil {
+		t.Error("task of other template must be kept")
+	}
+}
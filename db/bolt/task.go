@@ -1,8 +1,10 @@
 package bolt
 
 import (
+	"bytes"
 	"github.com/ansible-semaphore/semaphore/db"
 	"go.etcd.io/bbolt"
+	"sort"
 	"time"
 )
 
@@ -28,6 +30,17 @@ func (d *BoltDb) CreateTaskOutput(output db.TaskOutput) (db.TaskOutput, error) {
 	return newOutput.(db.TaskOutput), nil
 }
 
+func (d *BoltDb) CreateTaskOutputs(outputs []db.TaskOutput) error {
+	return d.db.Update(func(tx *bbolt.Tx) error {
+		for _, output := range outputs {
+			if _, err := d.createObjectTx(tx, output.TaskID, db.TaskOutputProps, output); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (d *BoltDb) getTasks(projectID int, templateID *int, params db.RetrieveQueryParams) (tasksWithTpl []db.TaskWithTpl, err error) {
 	var tasks []db.Task
 
@@ -148,3 +161,87 @@ func (d *BoltDb) GetTaskOutputs(projectID int, taskID int) (outputs []db.TaskOut
 
 	return
 }
+
+func (d *BoltDb) DeleteFinishedTasksBefore(before time.Time) (err error) {
+	var tasks []db.Task
+
+	err = d.getObjects(0, db.TaskProps, db.RetrieveQueryParams{}, func(tsk interface{}) bool {
+		task := tsk.(db.Task)
+		return task.Created.Before(before) && task.Status.IsFinished()
+	}, &tasks)
+
+	if err != nil {
+		return
+	}
+
+	for _, task := range tasks {
+		err = d.DeleteTaskWithOutputs(task.ProjectID, task.ID)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (d *BoltDb) TrimTemplateTasks(maxTasks int) (err error) {
+	var tasks []db.Task
+
+	err = d.getObjects(0, db.TaskProps, db.RetrieveQueryParams{}, nil, &tasks)
+	if err != nil {
+		return
+	}
+
+	// IDs of tasks are inverted (see TaskProps), so the most recent tasks go first
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].ID < tasks[j].ID
+	})
+
+	kept := make(map[int]int)
+
+	for _, task := range tasks {
+		kept[task.TemplateID]++
+		if kept[task.TemplateID] <= maxTasks || !task.Status.IsFinished() {
+			continue
+		}
+
+		err = d.DeleteTaskWithOutputs(task.ProjectID, task.ID)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (d *BoltDb) TrimTaskOutputs(maxLines int) error {
+	prefix := []byte(db.TaskOutputProps.TableName + "_")
+
+	return d.db.Update(func(tx *bbolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bbolt.Bucket) error {
+			if !bytes.HasPrefix(name, prefix) {
+				return nil
+			}
+
+			n := b.Stats().KeyN - maxLines
+			if n <= 0 {
+				return nil
+			}
+
+			// keys are ordered by creation, so the oldest lines go first
+			var keys [][]byte
+			c := b.Cursor()
+			for k, _ := c.First(); k != nil && len(keys) < n; k, _ = c.Next() {
+				keys = append(keys, append([]byte{}, k...))
+			}
+
+			for _, k := range keys {
+				if err := b.Delete(k); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	})
+}
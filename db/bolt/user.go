@@ -3,6 +3,7 @@ package bolt
 import (
 	"fmt"
 	"github.com/ansible-semaphore/semaphore/db"
+	"github.com/ansible-semaphore/semaphore/util"
 	"golang.org/x/crypto/bcrypt"
 	"time"
 )
@@ -56,7 +57,7 @@ func (d *BoltDb) CreateUser(user db.UserWithPwd) (newUser db.User, err error) {
 		return
 	}
 
-	pwdHash, err := bcrypt.GenerateFromPassword([]byte(user.Pwd), 11)
+	pwdHash, err := bcrypt.GenerateFromPassword([]byte(user.Pwd), util.Config().GetPasswordHashCost())
 
 	if err != nil {
 		return
@@ -95,7 +96,7 @@ func (d *BoltDb) UpdateUser(user db.UserWithPwd) error {
 
 	if user.Pwd != "" {
 		var pwdHash []byte
-		pwdHash, err := bcrypt.GenerateFromPassword([]byte(user.Pwd), 11)
+		pwdHash, err := bcrypt.GenerateFromPassword([]byte(user.Pwd), util.Config().GetPasswordHashCost())
 		if err != nil {
 			return err
 		}
@@ -114,7 +115,7 @@ func (d *BoltDb) UpdateUser(user db.UserWithPwd) error {
 }
 
 func (d *BoltDb) SetUserPassword(userID int, password string) error {
-	pwdHash, err := bcrypt.GenerateFromPassword([]byte(password), 11)
+	pwdHash, err := bcrypt.GenerateFromPassword([]byte(password), util.Config().GetPasswordHashCost())
 	if err != nil {
 		return err
 	}
@@ -8,7 +8,7 @@ import (
 )
 
 func CreateStore() db.Store {
-	config, err := util.Config.GetDBConfig()
+	config, err := util.Config().GetDBConfig()
 	if err != nil {
 		panic("Can not read configuration")
 	}
@@ -19,6 +19,8 @@ func CreateStore() db.Store {
 		return &bolt.BoltDb{}
 	case util.DbDriverPostgres:
 		return &sql.SqlDb{}
+	case util.DbDriverSQLite:
+		return &sql.SqlDb{}
 	default:
 		panic("Unsupported database dialect: " + config.Dialect)
 	}
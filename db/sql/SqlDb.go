@@ -1,20 +1,24 @@
 package sql
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	log "github.com/Sirupsen/logrus"
 	"github.com/ansible-semaphore/semaphore/db"
 	"github.com/ansible-semaphore/semaphore/util"
 	"github.com/go-gorp/gorp/v3"
-	_ "github.com/go-sql-driver/mysql" // imports mysql driver
+	"github.com/go-sql-driver/mysql"
 	"github.com/gobuffalo/packr"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/masterminds/squirrel"
+	_ "github.com/mattn/go-sqlite3" // imports sqlite driver
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type SqlDb struct {
@@ -140,22 +144,95 @@ func (d *SqlDb) selectAll(i interface{}, query string, args ...interface{}) ([]i
 }
 
 func connect() (*sql.DB, error) {
-	cfg, err := util.Config.GetDBConfig()
+	cfg, err := util.Config().GetDBConfig()
 	if err != nil {
 		return nil, err
 	}
 
+	if err = registerMySQLTLS(cfg); err != nil {
+		return nil, err
+	}
+
 	connectionString, err := cfg.GetConnectionString(true)
 	if err != nil {
 		return nil, err
 	}
 
-	dialect := cfg.Dialect
-	return sql.Open(dialect, connectionString)
+	var conn *sql.DB
+	if drv := getPasswordFileDriver(cfg); drv != nil {
+		conn = sql.OpenDB(&passwordFileConnector{cfg: cfg, driver: drv})
+	} else if conn, err = sql.Open(getDriverName(cfg.Dialect), connectionString); err != nil {
+		return nil, err
+	}
+
+	conn.SetMaxOpenConns(cfg.MaxOpenConns)
+	conn.SetMaxIdleConns(cfg.MaxIdleConns)
+	conn.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
+
+	return conn, nil
+}
+
+// passwordFileConnector builds the connection string on each new connection,
+// so the password file is re-read and rotated password is used after reconnect.
+type passwordFileConnector struct {
+	cfg    util.DbConfig
+	driver driver.Driver
+}
+
+func (c *passwordFileConnector) Connect(_ context.Context) (driver.Conn, error) {
+	connectionString, err := c.cfg.GetConnectionString(true)
+	if err != nil {
+		return nil, err
+	}
+	return c.driver.Open(connectionString)
+}
+
+func (c *passwordFileConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// getPasswordFileDriver returns the driver for passwordFileConnector or nil
+// if the password is not read from file.
+func getPasswordFileDriver(cfg util.DbConfig) driver.Driver {
+	if cfg.PasswordFile == "" {
+		return nil
+	}
+
+	switch cfg.Dialect {
+	case util.DbDriverMySQL:
+		return &mysql.MySQLDriver{}
+	case util.DbDriverPostgres:
+		return &pq.Driver{}
+	default:
+		return nil
+	}
+}
+
+// registerMySQLTLS registers TLS config built from TLS files of MySQL
+// connection, the connection string references it by name.
+func registerMySQLTLS(cfg util.DbConfig) error {
+	if cfg.Dialect != util.DbDriverMySQL || !cfg.HasCustomTLS() {
+		return nil
+	}
+
+	tlsConfig, err := cfg.GetTLSConfig()
+	if err != nil {
+		return err
+	}
+
+	return mysql.RegisterTLSConfig(util.MySQLCustomTLSName, tlsConfig)
+}
+
+// getDriverName returns the name of database/sql driver registered for the dialect.
+func getDriverName(dialect string) string {
+	if dialect == util.DbDriverSQLite {
+		return "sqlite3"
+	}
+	return dialect
 }
 
 func createDb() error {
-	cfg, err := util.Config.GetDBConfig()
+	cfg, err := util.Config().GetDBConfig()
 	if err != nil {
 		return err
 	}
@@ -164,6 +241,10 @@ func createDb() error {
 		return nil
 	}
 
+	if err = registerMySQLTLS(cfg); err != nil {
+		return err
+	}
+
 	connectionString, err := cfg.GetConnectionString(false)
 	if err != nil {
 		return err
@@ -270,28 +351,64 @@ func (d *SqlDb) PermanentConnection() bool {
 	return true
 }
 
-func (d *SqlDb) Connect(token string) {
+func (d *SqlDb) Ping(ctx context.Context) error {
+	return d.sql.Db.PingContext(ctx)
+}
+
+// connectAndPing connects to the database and creates it if it doesn't exist.
+func connectAndPing() (*sql.DB, error) {
 	sqlDb, err := connect()
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	if err := sqlDb.Ping(); err != nil {
-		if err = createDb(); err != nil {
-			panic(err)
-		}
+	if err = sqlDb.Ping(); err == nil {
+		return sqlDb, nil
+	}
 
-		sqlDb, err = connect()
-		if err != nil {
-			panic(err)
-		}
+	_ = sqlDb.Close()
+
+	if err = createDb(); err != nil {
+		return nil, err
+	}
 
-		if err = sqlDb.Ping(); err != nil {
-			panic(err)
+	sqlDb, err = connect()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = sqlDb.Ping(); err != nil {
+		_ = sqlDb.Close()
+		return nil, err
+	}
+
+	return sqlDb, nil
+}
+
+// connectWithRetry retries failed connection as configured by
+// db_connect_retries, because database can start later than Semaphore.
+func connectWithRetry() (*sql.DB, error) {
+	delay := time.Duration(util.Config().DbConnectRetryDelay) * time.Second
+
+	for retry := 0; ; retry++ {
+		sqlDb, err := connectAndPing()
+		if err == nil || retry >= util.Config().DbConnectRetries {
+			return sqlDb, err
 		}
+
+		log.Warnf("Can't connect to database, retry %d of %d in %v: %v", retry+1, util.Config().DbConnectRetries, delay, err)
+		time.Sleep(delay)
+		delay *= 2
 	}
+}
 
-	cfg, err := util.Config.GetDBConfig()
+func (d *SqlDb) Connect(token string) {
+	sqlDb, err := connectWithRetry()
+	if err != nil {
+		panic(err)
+	}
+
+	cfg, err := util.Config().GetDBConfig()
 	if err != nil {
 		panic(err)
 	}
@@ -303,9 +420,16 @@ func (d *SqlDb) Connect(token string) {
 		dialect = gorp.MySQLDialect{Engine: "InnoDB", Encoding: "UTF8"}
 	case util.DbDriverPostgres:
 		dialect = gorp.PostgresDialect{}
+	case util.DbDriverSQLite:
+		dialect = gorp.SqliteDialect{}
 	}
 
 	d.sql = &gorp.DbMap{Db: sqlDb, Dialect: dialect}
+	if util.Config().DbLogQueries {
+		d.sql.TraceOn("", queryLogger{
+			threshold: time.Duration(util.Config().DbSlowQueryThreshold) * time.Millisecond,
+		})
+	}
 
 	d.sql.AddTableWithName(db.APIToken{}, "user__token").SetKeys(false, "id")
 	d.sql.AddTableWithName(db.AccessKey{}, "access_key").SetKeys(true, "id")
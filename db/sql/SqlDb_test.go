@@ -1,8 +1,20 @@
 package sql
 
 import (
-	"github.com/go-gorp/gorp/v3"
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"os"
+	"path"
+	"strings"
 	"testing"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/ansible-semaphore/semaphore/db"
+	"github.com/ansible-semaphore/semaphore/util"
+	"github.com/go-gorp/gorp/v3"
 )
 
 func TestValidatePort(t *testing.T) {
@@ -11,4 +23,120 @@ func TestValidatePort(t *testing.T) {
 	if q != "select * from \"test\" where id = $1, email = $2" {
 		t.Error("invalid postgres query")
 	}
-}
\ No newline at end of file
+}
+
+func TestSplitSQLiteDefinitions(t *testing.T) {
+	defs := splitSQLiteDefinitions("`id` integer primary key autoincrement, `key_id` int null, " +
+		"`name` varchar(255) not null, foreign key (`key_id`) references access_key(`id`)")
+
+	if len(defs) != 4 {
+		t.Fatal("invalid definitions count")
+	}
+
+	if defs[2] != "`name` varchar(255) not null" {
+		t.Error("invalid column definition")
+	}
+
+	if !sqliteContainsIdentifier(defs[3], "key_id") || sqliteContainsIdentifier(defs[3], "key") {
+		t.Error("invalid foreign key definition matching")
+	}
+}
+
+func TestMigrateSQLite(t *testing.T) {
+	config := util.Config()
+	defer util.SetConfig(config)
+
+	util.SetConfig(&util.ConfigType{
+		Dialect: util.DbDriverSQLite,
+		SQLite: util.DbConfig{
+			Hostname: path.Join(t.TempDir(), "database.sqlite"),
+		},
+	})
+
+	store := SqlDb{}
+	store.Connect("test")
+	defer store.Close("test")
+
+	if err := db.Migrate(&store); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, version := range db.GetMigrations() {
+		applied, err := store.IsMigrationApplied(version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !applied {
+			t.Errorf("Migration %s was not applied", version.HumanoidVersion())
+		}
+	}
+}
+
+func TestQueryLogger(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	logger := queryLogger{threshold: 100 * time.Millisecond}
+	logger.Printf("%s%s [%s] (%v)", "", "select * from `user` where id=?", "1:secret", 10*time.Millisecond)
+	if buf.Len() != 0 {
+		t.Errorf("Fast query must not be logged: %v", buf.String())
+	}
+
+	logger.Printf("%s%s [%s] (%v)", "", "select * from `user` where id=?", "1:secret", 150*time.Millisecond)
+	if !strings.Contains(buf.String(), "select * from `user`") || !strings.Contains(buf.String(), "150ms") {
+		t.Errorf("Slow query must be logged with duration: %v", buf.String())
+	}
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("Query arguments must not be logged: %v", buf.String())
+	}
+}
+
+type dsnRecordingDriver struct {
+	dataSourceNames []string
+}
+
+func (d *dsnRecordingDriver) Open(name string) (driver.Conn, error) {
+	d.dataSourceNames = append(d.dataSourceNames, name)
+	return nil, errors.New("not connected")
+}
+
+func TestPasswordFileConnectorRereadsPassword(t *testing.T) {
+	passwordFile := path.Join(t.TempDir(), "db_password")
+
+	writePassword := func(password string) {
+		if err := os.WriteFile(passwordFile, []byte(password+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	drv := &dsnRecordingDriver{}
+	connector := &passwordFileConnector{
+		cfg: util.DbConfig{
+			Dialect:      util.DbDriverPostgres,
+			Hostname:     "db.example.com",
+			Username:     "semaphore",
+			PasswordFile: passwordFile,
+			DbName:       "semaphore",
+		},
+		driver: drv,
+	}
+
+	writePassword("first")
+	_, _ = connector.Connect(context.Background())
+
+	writePassword("second")
+	_, _ = connector.Connect(context.Background())
+
+	if len(drv.dataSourceNames) != 2 {
+		t.Fatalf("Expected 2 connections, got %d", len(drv.dataSourceNames))
+	}
+
+	if !strings.Contains(drv.dataSourceNames[0], ":first@") {
+		t.Errorf("Unexpected connection string: %v", drv.dataSourceNames[0])
+	}
+
+	if !strings.Contains(drv.dataSourceNames[1], ":second@") {
+		t.Errorf("Rotated password was not used: %v", drv.dataSourceNames[1])
+	}
+}
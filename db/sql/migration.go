@@ -20,6 +20,7 @@ var (
 	changeRE        = regexp.MustCompile(`^alter table \x60(\w+)\x60 change \x60(\w+)\x60 \x60(\w+)\x60 ([\w\(\)]+)( not null)?$`)
 	//dropForeignKeyRE  = regexp.MustCompile(`^alter table \x60(\w+)\x60 drop foreign key \x60(\w+)\x60 /\* postgres:\x60(\w*)\x60 mysql:\x60(\w*)\x60 \*/$`)
 	dropForeignKey2RE = regexp.MustCompile(`(?i)\bdrop foreign key\b`)
+	addConstraintRE   = regexp.MustCompile(`(?i)^alter table \S+\s+(add|drop) constraint\b`)
 )
 
 // getVersionPath is the humanoid version with the file format appended
@@ -47,7 +48,7 @@ func getVersionSQL(path string) (queries []string) {
 }
 
 // prepareMigration converts migration SQLite-query to current dialect.
-// Supported MySQL and Postgres dialects.
+// Supported MySQL, Postgres and SQLite dialects.
 func (d *SqlDb) prepareMigration(query string) string {
 	switch d.sql.Dialect.(type) {
 	case gorp.MySQLDialect:
@@ -88,6 +89,20 @@ func (d *SqlDb) prepareMigration(query string) string {
 		query = serialRE.ReplaceAllString(query, "serial primary key")
 		query = dropForeignKey2RE.ReplaceAllString(query, "drop constraint")
 		query = identifierQuoteRE.ReplaceAllString(query, "\"")
+	case gorp.SqliteDialect:
+		// SQLite can't drop constraints and doesn't enforce column types,
+		// so only renaming of columns is applied.
+		if dropForeignKey2RE.MatchString(query) || addConstraintRE.MatchString(query) {
+			return ""
+		}
+
+		m := changeRE.FindStringSubmatch(query)
+		if m != nil {
+			if m[2] == m[3] {
+				return ""
+			}
+			query = "alter table `" + m[1] + "` rename column `" + m[2] + "` to `" + m[3] + "`"
+		}
 	}
 	return query
 }
@@ -153,7 +168,7 @@ func (d *SqlDb) ApplyMigration(migration db.Migration) error {
 			continue
 		}
 
-		_, err = tx.Exec(q)
+		err = d.execMigrationQuery(tx, q)
 		if err != nil {
 			handleRollbackError(tx.Rollback())
 			log.Warnf("\n ERR! Query: %s\n\n", q)
@@ -0,0 +1,128 @@
+package sql
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-gorp/gorp/v3"
+)
+
+var (
+	dropColumnRE = regexp.MustCompile(`(?i)^alter table \x60?(\w+)\x60? drop column \x60?(\w+)\x60?;?$`)
+	constraintRE = regexp.MustCompile(`(?i)^(constraint|primary key|foreign key|unique|check)\b`)
+)
+
+// execMigrationQuery executes single migration query inside of transaction.
+// SQLite can't drop columns which are used in foreign keys or indexes,
+// so such queries are replaced by rebuilding of the table.
+func (d *SqlDb) execMigrationQuery(tx *gorp.Transaction, query string) error {
+	if _, ok := d.sql.Dialect.(gorp.SqliteDialect); ok {
+		if m := dropColumnRE.FindStringSubmatch(query); m != nil {
+			return sqliteDropColumn(tx, m[1], m[2])
+		}
+	}
+
+	_, err := tx.Exec(query)
+	return err
+}
+
+// sqliteDropColumn drops column as recommended by SQLite documentation:
+// creates new table without the column, copies data to it and replaces
+// the old table by the new one.
+func sqliteDropColumn(tx *gorp.Transaction, tableName string, columnName string) error {
+	createSQL, err := tx.SelectStr("select sql from sqlite_master where type = 'table' and name = ?", tableName)
+	if err != nil {
+		return err
+	}
+
+	var indexes []string
+	_, err = tx.Select(&indexes, "select sql from sqlite_master where type = 'index' and tbl_name = ? and sql is not null", tableName)
+	if err != nil {
+		return err
+	}
+
+	start := strings.Index(createSQL, "(")
+	end := strings.LastIndex(createSQL, ")")
+	if start < 0 || end < start {
+		return nil
+	}
+
+	var definitions []string
+	var columns []string
+
+	for _, def := range splitSQLiteDefinitions(createSQL[start+1 : end]) {
+		if constraintRE.MatchString(def) {
+			if !sqliteContainsIdentifier(def, columnName) {
+				definitions = append(definitions, def)
+			}
+			continue
+		}
+
+		name := strings.Trim(strings.Fields(def)[0], "`\"[]")
+		if strings.EqualFold(name, columnName) {
+			continue
+		}
+
+		definitions = append(definitions, def)
+		columns = append(columns, "`"+name+"`")
+	}
+
+	tmpTableName := tableName + "__tmp"
+	columnList := strings.Join(columns, ", ")
+
+	queries := []string{
+		"create table `" + tmpTableName + "` (" + strings.Join(definitions, ", ") + ")",
+		"insert into `" + tmpTableName + "` (" + columnList + ") select " + columnList + " from `" + tableName + "`",
+		"drop table `" + tableName + "`",
+		"alter table `" + tmpTableName + "` rename to `" + tableName + "`",
+	}
+
+	for _, index := range indexes {
+		if !sqliteContainsIdentifier(index, columnName) {
+			queries = append(queries, index)
+		}
+	}
+
+	for _, q := range queries {
+		if _, err = tx.Exec(q); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// splitSQLiteDefinitions splits body of create table statement
+// to column and constraint definitions.
+func splitSQLiteDefinitions(body string) (definitions []string) {
+	depth := 0
+	last := 0
+
+	for i, c := range body {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				definitions = append(definitions, strings.TrimSpace(body[last:i]))
+				last = i + 1
+			}
+		}
+	}
+
+	if def := strings.TrimSpace(body[last:]); def != "" {
+		definitions = append(definitions, def)
+	}
+
+	return
+}
+
+// sqliteContainsIdentifier checks if sql references the column either as quoted
+// identifier or as a member of column list.
+func sqliteContainsIdentifier(sql string, name string) bool {
+	quoted := regexp.QuoteMeta(name)
+	re := regexp.MustCompile(`(?i)[\x60"\[]` + quoted + `[\x60"\]]|[(,]\s*` + quoted + `\s*[),]`)
+	return re.MatchString(sql)
+}
@@ -0,0 +1,30 @@
+package sql
+
+import (
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// queryLogger writes SQL queries traced by gorp to the app log if they
+// take at least threshold. Query arguments aren't logged, they can contain secrets.
+type queryLogger struct {
+	threshold time.Duration
+}
+
+// Printf receives prefix, query, arguments and duration of the query.
+func (l queryLogger) Printf(format string, v ...interface{}) {
+	if len(v) != 4 {
+		return
+	}
+
+	duration, ok := v[3].(time.Duration)
+	if !ok || duration < l.threshold {
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"query":    v[1],
+		"duration": duration.String(),
+	}).Info("SQL query")
+}
@@ -3,7 +3,9 @@ package sql
 import (
 	"database/sql"
 	"github.com/ansible-semaphore/semaphore/db"
+	"github.com/ansible-semaphore/semaphore/lib"
 	"github.com/masterminds/squirrel"
+	"time"
 )
 
 func (d *SqlDb) CreateTask(task db.Task) (db.Task, error) {
@@ -31,6 +33,25 @@ func (d *SqlDb) CreateTaskOutput(output db.TaskOutput) (db.TaskOutput, error) {
 	return output, err
 }
 
+func (d *SqlDb) CreateTaskOutputs(outputs []db.TaskOutput) error {
+	tx, err := d.sql.Begin()
+	if err != nil {
+		return err
+	}
+
+	query := d.PrepareQuery("insert into task__output (task_id, task, output, time) VALUES (?, '', ?, ?)")
+
+	for _, output := range outputs {
+		_, err = tx.Exec(query, output.TaskID, output.Output, output.Time)
+		if err != nil {
+			handleRollbackError(tx.Rollback())
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
 func (d *SqlDb) getTasks(projectID int, templateID *int, params db.RetrieveQueryParams, tasks *[]db.TaskWithTpl) (err error) {
 	fields := "task.*"
 	fields += ", tpl.playbook as tpl_playbook" +
@@ -135,3 +156,87 @@ func (d *SqlDb) GetTaskOutputs(projectID int, taskID int) (output []db.TaskOutpu
 		taskID)
 	return
 }
+
+// finishedTaskCondition selects tasks which are not running or waiting.
+var finishedTaskCondition = "status in ('" + string(lib.TaskStoppedStatus) + "', '" +
+	string(lib.TaskSuccessStatus) + "', '" + string(lib.TaskFailStatus) + "')"
+
+// unreferencedTaskCondition excludes build tasks which are referenced by other
+// tasks, they can't be deleted because of the foreign key. The subquery is
+// wrapped in derived table because MySQL doesn't allow to select from
+// the table which rows are deleted.
+var unreferencedTaskCondition = "id not in (select build_task_id from " +
+	"(select build_task_id from task where build_task_id is not null) as build_task)"
+
+// deletableTaskCondition selects tasks which can be deleted by bulk deletes.
+var deletableTaskCondition = finishedTaskCondition + " and " + unreferencedTaskCondition
+
+func (d *SqlDb) DeleteFinishedTasksBefore(before time.Time) (err error) {
+	_, err = d.exec("delete from task__output where task_id in (select id from task where created < ? and "+deletableTaskCondition+")", before)
+	if err != nil {
+		return
+	}
+
+	_, err = d.exec("delete from task where created < ? and "+deletableTaskCondition, before)
+	return
+}
+
+func (d *SqlDb) TrimTemplateTasks(maxTasks int) (err error) {
+	var templateIDs []int
+	_, err = d.selectAll(&templateIDs, "select template_id from task group by template_id having count(*) > ?", maxTasks)
+	if err != nil {
+		return
+	}
+
+	for _, templateID := range templateIDs {
+		// id of the oldest task which is kept
+		var firstID int64
+		firstID, err = d.sql.SelectInt(
+			d.PrepareQuery("select id from task where template_id=? order by id desc limit 1 offset ?"),
+			templateID,
+			maxTasks-1)
+		if err != nil {
+			return
+		}
+
+		_, err = d.exec("delete from task__output where task_id in "+
+			"(select id from task where template_id=? and id < ? and "+deletableTaskCondition+")", templateID, firstID)
+		if err != nil {
+			return
+		}
+
+		_, err = d.exec("delete from task where template_id=? and id < ? and "+deletableTaskCondition, templateID, firstID)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (d *SqlDb) TrimTaskOutputs(maxLines int) (err error) {
+	var taskIDs []int
+	_, err = d.selectAll(&taskIDs, "select task_id from task__output group by task_id having count(*) > ?", maxLines)
+	if err != nil {
+		return
+	}
+
+	for _, taskID := range taskIDs {
+		// id of the oldest line which is kept
+		var firstID int64
+		firstID, err = d.sql.SelectInt(
+			d.PrepareQuery("select id from task__output where task_id=? order by id desc limit 1 offset ?"),
+			taskID,
+			maxLines-1)
+		if err != nil {
+			return
+		}
+
+		_, err = d.exec("delete from task__output where task_id=? and id < ?", taskID, firstID)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,114 @@
+package sql
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/ansible-semaphore/semaphore/db"
+	"github.com/ansible-semaphore/semaphore/lib"
+	"github.com/ansible-semaphore/semaphore/util"
+)
+
+func createTestSQLiteStore(t *testing.T) *SqlDb {
+	config := util.Config()
+	t.Cleanup(func() { util.SetConfig(config) })
+
+	util.SetConfig(&util.ConfigType{
+		Dialect: util.DbDriverSQLite,
+		SQLite: util.DbConfig{
+			Hostname: path.Join(t.TempDir(), "database.sqlite"),
+		},
+	})
+
+	store := &SqlDb{}
+	store.Connect("test")
+	t.Cleanup(func() { store.Close("test") })
+
+	if err := db.Migrate(store); err != nil {
+		t.Fatal(err)
+	}
+
+	return store
+}
+
+func taskExists(t *testing.T, store *SqlDb, taskID int) bool {
+	count, err := store.sql.SelectInt(store.PrepareQuery("select count(*) from task where id=?"), taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count > 0
+}
+
+func TestDeleteFinishedTasksBeforeKeepsReferencedBuildTask(t *testing.T) {
+	store := createTestSQLiteStore(t)
+
+	created := time.Now().Add(-time.Hour)
+
+	build, err := store.CreateTask(db.Task{ProjectID: 1, TemplateID: 1, Status: lib.TaskSuccessStatus, Created: created})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deploy, err := store.CreateTask(db.Task{ProjectID: 1, TemplateID: 2, Status: lib.TaskRunningStatus, Created: created, BuildTaskID: &build.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := store.CreateTask(db.Task{ProjectID: 1, TemplateID: 1, Status: lib.TaskFailStatus, Created: created})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = store.DeleteFinishedTasksBefore(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !taskExists(t, store, build.ID) {
+		t.Error("referenced build task must be kept")
+	}
+
+	if !taskExists(t, store, deploy.ID) {
+		t.Error("running task must be kept")
+	}
+
+	if taskExists(t, store, other.ID) {
+		t.Error("finished task must be deleted")
+	}
+}
+
+func TestTrimTemplateTasksKeepsReferencedBuildTask(t *testing.T) {
+	store := createTestSQLiteStore(t)
+
+	var tasks []db.Task
+	for i := 0; i < 4; i++ {
+		task := db.Task{ProjectID: 1, TemplateID: 1, Status: lib.TaskSuccessStatus, Created: time.Now()}
+		if i == 3 {
+			task.BuildTaskID = &tasks[0].ID
+		}
+
+		task, err := store.CreateTask(task)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := store.TrimTemplateTasks(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if !taskExists(t, store, tasks[0].ID) {
+		t.Error("referenced build task must be kept")
+	}
+
+	if taskExists(t, store, tasks[1].ID) {
+		t.Error("old task must be deleted")
+	}
+
+	for _, task := range tasks[2:] {
+		if !taskExists(t, store, task.ID) {
+			t.Errorf("recent task %d must be kept", task.ID)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package sql
 import (
 	"database/sql"
 	"github.com/ansible-semaphore/semaphore/db"
+	"github.com/ansible-semaphore/semaphore/util"
 	"github.com/masterminds/squirrel"
 	"golang.org/x/crypto/bcrypt"
 	"time"
@@ -35,7 +36,7 @@ func (d *SqlDb) CreateUser(user db.UserWithPwd) (newUser db.User, err error) {
 		return
 	}
 
-	pwdHash, err := bcrypt.GenerateFromPassword([]byte(user.Pwd), 11)
+	pwdHash, err := bcrypt.GenerateFromPassword([]byte(user.Pwd), util.Config().GetPasswordHashCost())
 
 	if err != nil {
 		return
@@ -64,7 +65,7 @@ func (d *SqlDb) UpdateUser(user db.UserWithPwd) error {
 
 	if user.Pwd != "" {
 		var pwdHash []byte
-		pwdHash, err = bcrypt.GenerateFromPassword([]byte(user.Pwd), 11)
+		pwdHash, err = bcrypt.GenerateFromPassword([]byte(user.Pwd), util.Config().GetPasswordHashCost())
 		if err != nil {
 			return err
 		}
@@ -92,7 +93,7 @@ func (d *SqlDb) UpdateUser(user db.UserWithPwd) error {
 }
 
 func (d *SqlDb) SetUserPassword(userID int, password string) error {
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), 11)
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), util.Config().GetPasswordHashCost())
 	if err != nil {
 		return err
 	}
@@ -21,7 +21,7 @@ func (p AnsiblePlaybook) makeCmd(command string, args []string, environmentVars
 	cmd.Dir = p.GetFullPath()
 
 	cmd.Env = os.Environ()
-	cmd.Env = append(cmd.Env, fmt.Sprintf("HOME=%s", util.Config.TmpPath))
+	cmd.Env = append(cmd.Env, fmt.Sprintf("HOME=%s", util.Config().TmpPath))
 	cmd.Env = append(cmd.Env, fmt.Sprintf("PWD=%s", cmd.Dir))
 	cmd.Env = append(cmd.Env, "PYTHONUNBUFFERED=1")
 	cmd.Env = append(cmd.Env, "ANSIBLE_FORCE_COLOR=True")
@@ -54,7 +54,7 @@ func (p AnsiblePlaybook) runCmd(command string, args []string) error {
 }
 
 func (p AnsiblePlaybook) RunPlaybook(args []string, environmentVars *[]string, cb func(*os.Process)) error {
-	cmd := p.makeCmd("ansible-playbook", args, environmentVars)
+	cmd := p.makeCmd(util.Config().GetAnsiblePath(), args, environmentVars)
 	p.Logger.LogCmd(cmd)
 	cmd.Stdin = strings.NewReader("")
 	err := cmd.Start()
@@ -66,7 +66,7 @@ func (p AnsiblePlaybook) RunPlaybook(args []string, environmentVars *[]string, c
 }
 
 func (p AnsiblePlaybook) RunGalaxy(args []string) error {
-	return p.runCmd("ansible-galaxy", args)
+	return p.runCmd(util.Config().GetAnsibleGalaxyPath(), args)
 }
 
 func (p AnsiblePlaybook) GetFullPath() (path string) {
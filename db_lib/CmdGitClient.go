@@ -15,22 +15,22 @@ type CmdGitClient struct {
 }
 
 func (c CmdGitClient) makeCmd(r GitRepository, targetDir GitRepositoryDirType, args ...string) *exec.Cmd {
-	cmd := exec.Command("git") //nolint: gas
+	cmd := exec.Command(util.Config().GetGitPath()) //nolint: gas
 
 	cmd.Env = os.Environ()
 	cmd.Env = append(cmd.Env, fmt.Sprintln("GIT_TERMINAL_PROMPT=0"))
 	if r.Repository.SSHKey.Type == db.AccessKeySSH {
 		cmd.Env = append(cmd.Env, fmt.Sprintf("SSH_AUTH_SOCK=%s", c.keyInstallation.SshAgent.SocketFile))
 		sshCmd := "ssh -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null"
-		if util.Config.SshConfigPath != "" {
-			sshCmd += " -F " + util.Config.SshConfigPath
+		if util.Config().SshConfigPath != "" {
+			sshCmd += " -F " + util.Config().SshConfigPath
 		}
 		cmd.Env = append(cmd.Env, fmt.Sprintf("GIT_SSH_COMMAND=%s", sshCmd))
 	}
 
 	switch targetDir {
 	case GitRepositoryTmpDir:
-		cmd.Dir = util.Config.TmpPath
+		cmd.Dir = util.Config().GetRepoPath()
 	case GitRepositoryRepoDir:
 		cmd.Dir = r.GetFullPath()
 	default:
@@ -3,7 +3,7 @@ package db_lib
 import "github.com/ansible-semaphore/semaphore/util"
 
 func CreateDefaultGitClient() GitClient {
-	switch util.Config.GitClientId {
+	switch util.Config().GitClientId {
 	case util.GoGitClientId:
 		return CreateGoGitClient()
 	case util.CmdGitClientId:
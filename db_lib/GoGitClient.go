@@ -60,7 +60,7 @@ func openRepository(r GitRepository, targetDir GitRepositoryDirType) (*git.Repos
 
 	switch targetDir {
 	case GitRepositoryTmpDir:
-		dir = util.Config.TmpPath
+		dir = util.Config().GetRepoPath()
 	case GitRepositoryRepoDir:
 		dir = r.GetFullPath()
 	default:
@@ -18,6 +18,8 @@ require (
 	github.com/gorilla/websocket v1.4.1
 	github.com/lib/pq v1.2.0
 	github.com/masterminds/squirrel v0.0.0-20170825200431-a6b93000bd21
+	github.com/mattn/go-sqlite3 v1.14.16
+	github.com/pkg/errors v0.9.1
 	github.com/robfig/cron/v3 v3.0.1
 	github.com/snikch/goodman v0.0.0-20171125024755-10e37e294daa
 	github.com/spf13/cobra v1.2.1
@@ -25,6 +27,7 @@ require (
 	go.etcd.io/bbolt v1.3.2
 	golang.org/x/crypto v0.3.0
 	golang.org/x/oauth2 v0.7.0
+	gopkg.in/yaml.v3 v3.0.0
 )
 
 require (
@@ -48,7 +51,6 @@ require (
 	github.com/lann/builder v0.0.0-20180216234317-1b87b36280d0 // indirect
 	github.com/lann/ps v0.0.0-20150810152359-62de8c46ede0 // indirect
 	github.com/mitchellh/go-homedir v1.1.0 // indirect
-	github.com/pkg/errors v0.9.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/sergi/go-diff v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
@@ -61,5 +63,4 @@ require (
 	gopkg.in/airbrake/gobrake.v2 v2.0.9 // indirect
 	gopkg.in/gemnasium/logrus-airbrake-hook.v2 v2.1.2 // indirect
 	gopkg.in/warnings.v0 v0.1.2 // indirect
-	gopkg.in/yaml.v3 v3.0.0 // indirect
 )
@@ -253,7 +253,7 @@ func (p *JobPool) Run() {
 
 				p.sendProgress()
 
-				if util.Config.Runner.OneOff && len(p.runningJobs) > 0 && !p.hasRunningJobs() {
+				if util.Config().Runner.OneOff && len(p.runningJobs) > 0 && !p.hasRunningJobs() {
 					os.Exit(0)
 				}
 
@@ -266,9 +266,9 @@ func (p *JobPool) Run() {
 
 func (p *JobPool) sendProgress() {
 
-	client := &http.Client{}
+	client := util.Config().NewHTTPClient()
 
-	url := util.Config.Runner.ApiURL + "/runners/" + strconv.Itoa(p.config.RunnerID)
+	url := util.Config().Runner.ApiURL + "/runners/" + strconv.Itoa(p.config.RunnerID)
 
 	body := RunnerProgress{
 		Jobs: nil,
@@ -314,10 +314,10 @@ func (p *JobPool) tryRegisterRunner() bool {
 
 	log.Info("Trying to register on server")
 
-	_, err := os.Stat(util.Config.Runner.ConfigFile)
+	_, err := os.Stat(util.Config().Runner.ConfigFile)
 
 	if err == nil {
-		configBytes, err2 := os.ReadFile(util.Config.Runner.ConfigFile)
+		configBytes, err2 := os.ReadFile(util.Config().Runner.ConfigFile)
 
 		if err2 != nil {
 			panic(err2)
@@ -340,18 +340,18 @@ func (p *JobPool) tryRegisterRunner() bool {
 		panic(err)
 	}
 
-	if util.Config.Runner.RegistrationToken == "" {
+	if util.Config().Runner.RegistrationToken == "" {
 		panic("registration token cannot be empty")
 	}
 
-	client := &http.Client{}
+	client := util.Config().NewHTTPClient()
 
-	url := util.Config.Runner.ApiURL + "/runners"
+	url := util.Config().Runner.ApiURL + "/runners"
 
 	jsonBytes, err := json.Marshal(RunnerRegistration{
-		RegistrationToken: util.Config.Runner.RegistrationToken,
-		Webhook:           util.Config.Runner.Webhook,
-		MaxParallelTasks:  util.Config.Runner.MaxParallelTasks,
+		RegistrationToken: util.Config().Runner.RegistrationToken,
+		Webhook:           util.Config().Runner.Webhook,
+		MaxParallelTasks:  util.Config().Runner.MaxParallelTasks,
 	})
 
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonBytes))
@@ -385,7 +385,7 @@ func (p *JobPool) tryRegisterRunner() bool {
 		panic("cannot save runner config")
 	}
 
-	err = os.WriteFile(util.Config.Runner.ConfigFile, configBytes, 0644)
+	err = os.WriteFile(util.Config().Runner.ConfigFile, configBytes, 0644)
 
 	p.config = &config
 
@@ -397,9 +397,9 @@ func (p *JobPool) tryRegisterRunner() bool {
 // checkNewJobs tries to find runner to queued jobs
 func (p *JobPool) checkNewJobs() {
 
-	client := &http.Client{}
+	client := util.Config().NewHTTPClient()
 
-	url := util.Config.Runner.ApiURL + "/runners/" + strconv.Itoa(p.config.RunnerID)
+	url := util.Config().Runner.ApiURL + "/runners/" + strconv.Itoa(p.config.RunnerID)
 
 	req, err := http.NewRequest("GET", url, nil)
 
@@ -442,7 +442,7 @@ func (p *JobPool) checkNewJobs() {
 		}
 	}
 
-	if util.Config.Runner.OneOff {
+	if util.Config().Runner.OneOff {
 		if len(p.queue) > 0 || len(p.runningJobs) > 0 {
 			return
 		}
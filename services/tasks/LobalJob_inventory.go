@@ -33,7 +33,7 @@ func (t *LocalJob) installInventory() (err error) {
 func (t *LocalJob) installStaticInventory() error {
 	t.Log("installing static inventory")
 
-	path := util.Config.TmpPath + "/inventory_" + strconv.Itoa(t.Task.ID)
+	path := util.Config().TmpPath + "/inventory_" + strconv.Itoa(t.Task.ID)
 	if t.Inventory.Type == db.InventoryStaticYaml {
 		path += ".yml"
 	}
@@ -122,7 +122,7 @@ func (t *LocalJob) getPlaybookArgs(username string, incomingVersion *string) (ar
 	case db.InventoryFile:
 		inventory = t.Inventory.Inventory
 	case db.InventoryStatic, db.InventoryStaticYaml:
-		inventory = util.Config.TmpPath + "/inventory_" + strconv.Itoa(t.Task.ID)
+		inventory = util.Config().TmpPath + "/inventory_" + strconv.Itoa(t.Task.ID)
 		if t.Inventory.Type == db.InventoryStaticYaml {
 			inventory += ".yml"
 		}
@@ -262,11 +262,16 @@ func (t *LocalJob) Run(username string, incomingVersion *string) (err error) {
 func (t *LocalJob) prepareRun() error {
 	t.Log("Preparing: " + strconv.Itoa(t.Task.ID))
 
-	if err := checkTmpDir(util.Config.TmpPath); err != nil {
+	if err := checkTmpDir(util.Config().TmpPath); err != nil {
 		t.Log("Creating tmp dir failed: " + err.Error())
 		return err
 	}
 
+	if err := checkTmpDir(util.Config().GetRepoPath()); err != nil {
+		t.Log("Creating repository dir failed: " + err.Error())
+		return err
+	}
+
 	if t.Repository.GetType() == db.RepositoryLocal {
 		if _, err := os.Stat(t.Repository.GitURL); err != nil {
 			t.Log("Failed in finding static repository at " + t.Repository.GitURL + ": " + err.Error())
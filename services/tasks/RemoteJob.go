@@ -7,6 +7,7 @@ import (
 	"github.com/ansible-semaphore/semaphore/db"
 	"github.com/ansible-semaphore/semaphore/db_lib"
 	"github.com/ansible-semaphore/semaphore/lib"
+	"github.com/ansible-semaphore/semaphore/util"
 	"net/http"
 	"time"
 )
@@ -48,7 +49,7 @@ func callRunnerWebhook(runner *db.Runner, tsk *TaskRunner, action string) (err e
 		return
 	}
 
-	client := &http.Client{}
+	client := util.Config().NewHTTPClient()
 
 	var req *http.Request
 	req, err = http.NewRequest("POST", runner.Webhook, bytes.NewBuffer(jsonBytes))
@@ -101,7 +102,7 @@ func (t *RemoteJob) Run(username string, incomingVersion *string) (err error) {
 
 	for _, r := range runners {
 		n := t.taskPool.GetNumberOfRunningTasksOfRunner(r.ID)
-		if n < r.MaxParallelTasks {
+		if n < util.Config().GetNodeMaxParallelTasks(r.MaxParallelTasks) {
 			runner = &r
 			break
 		}
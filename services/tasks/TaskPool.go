@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
@@ -42,6 +43,9 @@ type TaskPool struct {
 	store db.Store
 
 	resourceLocker chan *resourceLock
+
+	// cleanupRunning is set while cleanup is in progress.
+	cleanupRunning atomic.Bool
 }
 
 func (p *TaskPool) GetNumberOfRunningTasksOfRunner(runnerID int) (res int) {
@@ -84,10 +88,20 @@ func (p *TaskPool) GetTask(id int) (task *TaskRunner) {
 // nolint: gocyclo
 func (p *TaskPool) Run() {
 	ticker := time.NewTicker(5 * time.Second)
+	cleanupTicker := time.NewTicker(cleanupInterval)
+	logFlushTicker := time.NewTicker(getTaskLogFlushInterval())
+
+	// task output buffered before writing to database
+	var logBuffer []db.TaskOutput
 
 	defer func() {
 		close(p.resourceLocker)
 		ticker.Stop()
+		cleanupTicker.Stop()
+		logFlushTicker.Stop()
+		if len(logBuffer) > 0 {
+			p.flushLogs(logBuffer)
+		}
 	}()
 
 	// Lock or unlock resources when running a TaskRunner
@@ -124,16 +138,21 @@ func (p *TaskPool) Run() {
 	for {
 		select {
 		case record := <-p.logger: // new log message which should be put to database
-			db.StoreSession(p.store, "logger", func() {
-				_, err := p.store.CreateTaskOutput(db.TaskOutput{
-					TaskID: record.task.Task.ID,
-					Output: record.output,
-					Time:   record.time,
-				})
-				if err != nil {
-					log.Error(err)
-				}
+			logBuffer = append(logBuffer, db.TaskOutput{
+				TaskID: record.task.Task.ID,
+				Output: record.output,
+				Time:   record.time,
 			})
+			if len(logBuffer) >= util.Config().TaskLogBufferSize {
+				p.flushLogs(logBuffer)
+				logBuffer = nil
+			}
+
+		case <-logFlushTicker.C: // write buffered log messages to database
+			if len(logBuffer) > 0 {
+				p.flushLogs(logBuffer)
+				logBuffer = nil
+			}
 
 		case task := <-p.register: // new task created by API or schedule
 
@@ -146,6 +165,9 @@ func (p *TaskPool) Run() {
 				task.saveStatus()
 			})
 
+		case <-cleanupTicker.C: // timer 1 hour
+			go p.cleanup()
+
 		case <-ticker.C: // timer 5 seconds
 			if len(p.queue) == 0 {
 				break
@@ -177,19 +199,74 @@ func (p *TaskPool) Run() {
 	}
 }
 
+// defaultTaskLogFlushInterval is used if TaskLogFlushInterval is 0.
+const defaultTaskLogFlushInterval = 200 * time.Millisecond
+
+// getTaskLogFlushInterval returns period of writing buffered task output.
+func getTaskLogFlushInterval() time.Duration {
+	interval := time.Duration(util.Config().TaskLogFlushInterval) * time.Millisecond
+	if interval <= 0 {
+		// time.NewTicker panics on non-positive interval
+		interval = defaultTaskLogFlushInterval
+	}
+	return interval
+}
+
+// flushLogs writes buffered task output to database.
+func (p *TaskPool) flushLogs(outputs []db.TaskOutput) {
+	db.StoreSession(p.store, "logger", func() {
+		if err := p.store.CreateTaskOutputs(outputs); err != nil {
+			log.Error(err)
+		}
+	})
+}
+
 func (p *TaskPool) blocks(t *TaskRunner) bool {
 
-	if util.Config.MaxParallelTasks > 0 && len(p.runningTasks) >= util.Config.MaxParallelTasks {
-		return true
+	// zero MaxParallelTasks means unlimited number of tasks
+	limit := util.Config().MaxParallelTasks
+
+	switch util.Config().ConcurrencyMode {
+	case util.ConcurrencyModeProject:
+		if limit > 0 && len(p.activeProj[t.Task.ProjectID]) >= limit {
+			return true
+		}
+	case util.ConcurrencyModeTemplate:
+		// the limit replaces default restriction of one running task per template,
+		// zero limit keeps the default
+		templateLimit := limit
+		if templateLimit == 0 {
+			templateLimit = 1
+		}
+		templateTasks := 0
+		for _, r := range p.activeProj[t.Task.ProjectID] {
+			if r.Template.ID == t.Task.TemplateID {
+				templateTasks++
+			}
+		}
+		if templateTasks >= templateLimit {
+			return true
+		}
+	default:
+		if limit > 0 && len(p.runningTasks) >= limit {
+			return true
+		}
+		// tasks of remote runners are limited on selection of the runner
+		if nodeLimit := util.Config().GetNodeMaxParallelTasks(0); !util.Config().UseRemoteRunner &&
+			nodeLimit > 0 && len(p.runningTasks) >= nodeLimit {
+			return true
+		}
 	}
 
 	if p.activeProj[t.Task.ProjectID] == nil || len(p.activeProj[t.Task.ProjectID]) == 0 {
 		return false
 	}
 
-	for _, r := range p.activeProj[t.Task.ProjectID] {
-		if r.Template.ID == t.Task.TemplateID {
-			return true
+	if util.Config().ConcurrencyMode != util.ConcurrencyModeTemplate {
+		for _, r := range p.activeProj[t.Task.ProjectID] {
+			if r.Template.ID == t.Task.TemplateID {
+				return true
+			}
 		}
 	}
 
@@ -348,7 +425,7 @@ func (p *TaskPool) AddTask(taskObj db.Task, userID *int, projectID int) (newTask
 
 	var job Job
 
-	if util.Config.UseRemoteRunner {
+	if util.Config().UseRemoteRunner {
 		job = &RemoteJob{
 			Task:        taskRunner.Task,
 			Template:    taskRunner.Template,
@@ -0,0 +1,53 @@
+package tasks
+
+import (
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/ansible-semaphore/semaphore/db"
+	"github.com/ansible-semaphore/semaphore/util"
+)
+
+// cleanupInterval is period of deleting old tasks and task output.
+const cleanupInterval = time.Hour
+
+// cleanup applies retention settings: deletes finished tasks older than
+// TaskRetentionDays, keeps MaxTaskHistoryPerTemplate tasks of each template
+// and trims task output to MaxTaskLogSize lines.
+// On big databases it can take longer than cleanupInterval, so it is
+// skipped while the previous cleanup is running.
+func (p *TaskPool) cleanup() {
+	if !p.cleanupRunning.CompareAndSwap(false, true) {
+		return
+	}
+	defer p.cleanupRunning.Store(false)
+
+	retentionDays := util.Config().TaskRetentionDays
+	maxHistory := util.Config().MaxTaskHistoryPerTemplate
+	maxLogSize := util.Config().MaxTaskLogSize
+
+	if retentionDays == 0 && maxHistory == 0 && maxLogSize == 0 {
+		return
+	}
+
+	db.StoreSession(p.store, "cleanup", func() {
+		if retentionDays > 0 {
+			before := time.Now().AddDate(0, 0, -retentionDays)
+			if err := p.store.DeleteFinishedTasksBefore(before); err != nil {
+				log.Error(err)
+			}
+		}
+
+		if maxHistory > 0 {
+			if err := p.store.TrimTemplateTasks(maxHistory); err != nil {
+				log.Error(err)
+			}
+		}
+
+		if maxLogSize > 0 {
+			if err := p.store.TrimTaskOutputs(maxLogSize); err != nil {
+				log.Error(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,195 @@
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ansible-semaphore/semaphore/db"
+	"github.com/ansible-semaphore/semaphore/util"
+)
+
+// waitTaskOutputs waits until the task has count output records in store.
+func waitTaskOutputs(t *testing.T, store db.Store, task db.Task, count int) {
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		var outputs []db.TaskOutput
+		var err error
+
+		db.StoreSession(store, "test", func() {
+			outputs, err = store.GetTaskOutputs(task.ProjectID, task.ID)
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(outputs) == count {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("Task has %v output records instead of %v", len(outputs), count)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func testTaskLogFlush(t *testing.T, bufferSize int, flushInterval int, records int) {
+	util.SetConfig(&util.ConfigType{
+		TaskLogBufferSize:    bufferSize,
+		TaskLogFlushInterval: flushInterval,
+	})
+
+	store := CreateBoltDB()
+	pool := CreateTaskPool(store)
+
+	var task db.Task
+	var err error
+
+	db.StoreSession(store, "", func() {
+		task, err = store.CreateTask(db.Task{})
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go pool.Run()
+
+	taskRunner := TaskRunner{
+		Task: task,
+		pool: &pool,
+	}
+
+	for i := 0; i < records; i++ {
+		taskRunner.Log("line")
+	}
+
+	waitTaskOutputs(t, store, task, records)
+}
+
+func TestTaskLogFlushOnBufferSize(t *testing.T) {
+	// the interval is too long to flush the buffer during the test
+	testTaskLogFlush(t, 3, int(time.Hour/time.Millisecond), 3)
+}
+
+func TestTaskLogFlushOnInterval(t *testing.T) {
+	// the buffer isn't filled, it is flushed by the ticker
+	testTaskLogFlush(t, 100, 10, 2)
+}
+
+func TestGetTaskLogFlushIntervalDefault(t *testing.T) {
+	util.SetConfig(&util.ConfigType{TaskLogFlushInterval: 0})
+
+	if interval := getTaskLogFlushInterval(); interval != defaultTaskLogFlushInterval {
+		t.Errorf("Expected default interval for 0, got %v", interval)
+	}
+}
+
+func TestTaskPoolCleanupSkippedWhileRunning(t *testing.T) {
+	util.SetConfig(&util.ConfigType{MaxTaskLogSize: 1})
+
+	store := CreateBoltDB()
+
+	var task db.Task
+	var err error
+
+	db.StoreSession(store, "", func() {
+		task, err = store.CreateTask(db.Task{})
+		if err == nil {
+			err = store.CreateTaskOutputs([]db.TaskOutput{
+				{TaskID: task.ID, Output: "1", Time: time.Now()},
+				{TaskID: task.ID, Output: "2", Time: time.Now()},
+			})
+		}
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := CreateTaskPool(store)
+
+	pool.cleanupRunning.Store(true)
+	pool.cleanup()
+	waitTaskOutputs(t, store, task, 2)
+
+	pool.cleanupRunning.Store(false)
+	pool.cleanup()
+	waitTaskOutputs(t, store, task, 1)
+}
+
+func TestTaskPoolBlocks(t *testing.T) {
+	store := CreateBoltDB()
+	store.Connect("test")
+	defer store.Close("test")
+
+	project, err := store.CreateProject(db.Project{Name: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherProjectID := project.ID + 1
+
+	runner := func(id int, projectID int, templateID int) *TaskRunner {
+		return &TaskRunner{
+			Task:     db.Task{ID: id, ProjectID: projectID, TemplateID: templateID},
+			Template: db.Template{ID: templateID, ProjectID: projectID},
+		}
+	}
+
+	sameTemplate := func(id int) *TaskRunner { return runner(id, project.ID, 1) }
+	otherTemplate := func(id int) *TaskRunner { return runner(id, project.ID, id+100) }
+	otherProject := func(id int) *TaskRunner { return runner(id, otherProjectID, 1) }
+
+	tests := []struct {
+		name      string
+		mode      string
+		limit     int
+		nodeLimit int
+		running   []*TaskRunner
+		blocks    bool
+	}{
+		{"default, no limit, other template", "", 0, 0, []*TaskRunner{otherTemplate(1)}, false},
+		{"default, no limit, same template", "", 0, 0, []*TaskRunner{sameTemplate(1)}, true},
+		{"default, limit not reached", "", 2, 0, []*TaskRunner{otherProject(1)}, false},
+		{"default, limit reached", "", 2, 0, []*TaskRunner{otherProject(1), otherProject(2)}, true},
+		{"node, no limit, same template", "node", 0, 1, []*TaskRunner{sameTemplate(1)}, true},
+		{"node, node limit reached", "node", 0, 1, []*TaskRunner{otherProject(1)}, true},
+		{"node, node limit not reached", "node", 0, 2, []*TaskRunner{otherProject(1)}, false},
+		{"node, limit reached", "node", 1, 2, []*TaskRunner{otherProject(1)}, true},
+		{"project, no limit, other templates", "project", 0, 0, []*TaskRunner{otherTemplate(1), otherTemplate(2), otherTemplate(3)}, false},
+		{"project, no limit, same template", "project", 0, 0, []*TaskRunner{sameTemplate(1)}, true},
+		{"project, limit reached", "project", 2, 0, []*TaskRunner{otherTemplate(1), otherTemplate(2)}, true},
+		{"project, limit reached by other project", "project", 2, 0, []*TaskRunner{otherProject(1), otherProject(2)}, false},
+		{"project, limit not reached, same template", "project", 2, 0, []*TaskRunner{sameTemplate(1)}, true},
+		{"template, no limit, same template", "template", 0, 0, []*TaskRunner{sameTemplate(1)}, true},
+		{"template, no limit, other template", "template", 0, 0, []*TaskRunner{otherTemplate(1)}, false},
+		{"template, limit not reached", "template", 2, 0, []*TaskRunner{sameTemplate(1), otherTemplate(2)}, false},
+		{"template, limit reached", "template", 2, 0, []*TaskRunner{sameTemplate(1), sameTemplate(2)}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			util.SetConfig(&util.ConfigType{
+				ConcurrencyMode:      test.mode,
+				MaxParallelTasks:     test.limit,
+				NodeMaxParallelTasks: test.nodeLimit,
+			})
+
+			pool := CreateTaskPool(store)
+			for _, r := range test.running {
+				if pool.activeProj[r.Task.ProjectID] == nil {
+					pool.activeProj[r.Task.ProjectID] = make(map[int]*TaskRunner)
+				}
+				pool.activeProj[r.Task.ProjectID][r.Task.ID] = r
+				pool.runningTasks[r.Task.ID] = r
+			}
+
+			if blocks := pool.blocks(sameTemplate(100)); blocks != test.blocks {
+				t.Errorf("blocks() = %v, expected %v", blocks, test.blocks)
+			}
+		})
+	}
+}
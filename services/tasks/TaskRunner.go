@@ -89,13 +89,27 @@ func (t *TaskRunner) SetStatus(status lib.TaskStatus) {
 
 	t.saveStatus()
 
+	if !util.Config().AlertsEnabled {
+		return
+	}
+
 	if status == lib.TaskFailStatus {
+		t.sendPagerDutyAlert()
+	}
+
+	// these alerts are sent on success only if it is enabled in config
+	if status == lib.TaskFailStatus || (status == lib.TaskSuccessStatus && util.Config().AlertOnSuccess && !t.Template.SuppressSuccessAlerts) {
 		t.sendMailAlert()
+		t.sendTeamsAlert()
+		t.sendDiscordAlert()
+		t.sendWebhookAlert()
+		t.sendNotificationAlerts()
 	}
 
 	if status == lib.TaskSuccessStatus || status == lib.TaskFailStatus {
 		t.sendTelegramAlert()
 		t.sendSlackAlert()
+		t.sendGotifyAlert()
 	}
 }
 
@@ -203,6 +217,14 @@ func (t *TaskRunner) run() {
 
 	}
 
+	if util.Config().MaxTaskDuration > 0 {
+		timer := time.AfterFunc(time.Duration(util.Config().MaxTaskDuration)*time.Second, func() {
+			t.Log("Task exceeded max duration of " + strconv.Itoa(util.Config().MaxTaskDuration) + " seconds and will be killed")
+			t.kill()
+		})
+		defer timer.Stop()
+	}
+
 	err = t.job.Run(username, incomingVersion)
 
 	if err != nil {
@@ -1,8 +1,11 @@
 package tasks
 
 import (
+	"fmt"
 	"github.com/ansible-semaphore/semaphore/db_lib"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"strconv"
@@ -12,6 +15,7 @@ import (
 
 	"github.com/ansible-semaphore/semaphore/db"
 	"github.com/ansible-semaphore/semaphore/db/bolt"
+	"github.com/ansible-semaphore/semaphore/lib"
 	"github.com/ansible-semaphore/semaphore/util"
 )
 
@@ -25,9 +29,9 @@ func CreateBoltDB() db.Store {
 }
 
 func TestTaskRunnerRun(t *testing.T) {
-	util.Config = &util.ConfigType{
+	util.SetConfig(&util.ConfigType{
 		TmpPath: "/tmp",
-	}
+	})
 
 	store := CreateBoltDB()
 
@@ -67,10 +71,160 @@ func TestTaskRunnerRun(t *testing.T) {
 	taskRunner.run()
 }
 
+// blockingJob runs until it is killed.
+type blockingJob struct {
+	killed chan struct{}
+}
+
+func (j *blockingJob) Run(username string, incomingVersion *string) error {
+	<-j.killed
+	return fmt.Errorf("job killed")
+}
+
+func (j *blockingJob) Kill() {
+	close(j.killed)
+}
+
+func TestTaskRunnerMaxTaskDuration(t *testing.T) {
+	util.SetConfig(&util.ConfigType{
+		TmpPath:              "/tmp",
+		MaxTaskDuration:      1,
+		TaskLogBufferSize:    100,
+		TaskLogFlushInterval: 10,
+	})
+
+	store := CreateBoltDB()
+
+	pool := CreateTaskPool(store)
+
+	go pool.Run()
+
+	var task db.Task
+
+	var err error
+
+	db.StoreSession(store, "", func() {
+		task, err = store.CreateTask(db.Task{})
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	job := &blockingJob{killed: make(chan struct{})}
+
+	taskRunner := TaskRunner{
+		Task: task,
+		pool: &pool,
+		job:  job,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		taskRunner.run()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Task was not killed after max duration")
+	}
+
+	if taskRunner.Task.Status != lib.TaskFailStatus {
+		t.Errorf("Unexpected task status: %v", taskRunner.Task.Status)
+	}
+
+	expected := "Task exceeded max duration of 1 seconds and will be killed"
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		var outputs []db.TaskOutput
+
+		db.StoreSession(store, "test", func() {
+			outputs, err = store.GetTaskOutputs(task.ProjectID, task.ID)
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, output := range outputs {
+			if output.Output == expected {
+				return
+			}
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("Max duration message was not written to task log")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestTaskRunnerNotificationURLsOnSuccess(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	conf := &util.ConfigType{
+		TmpPath:          "/tmp",
+		AlertsEnabled:    true,
+		NotificationURLs: []string{"json://" + strings.TrimPrefix(server.URL, "http://") + "/hook"},
+	}
+	util.SetConfig(conf)
+
+	store := CreateBoltDB()
+	pool := CreateTaskPool(store)
+
+	newTaskRunner := func() *TaskRunner {
+		var task db.Task
+		var err error
+
+		db.StoreSession(store, "", func() {
+			task, err = store.CreateTask(db.Task{Status: lib.TaskRunningStatus})
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return &TaskRunner{
+			Task:  task,
+			pool:  &pool,
+			alert: true,
+		}
+	}
+
+	taskRunner := newTaskRunner()
+	db.StoreSession(store, "", func() {
+		taskRunner.SetStatus(lib.TaskSuccessStatus)
+	})
+
+	if requests != 0 {
+		t.Errorf("Notification was sent on success without AlertOnSuccess")
+	}
+
+	conf.AlertOnSuccess = true
+
+	taskRunner = newTaskRunner()
+	db.StoreSession(store, "", func() {
+		taskRunner.SetStatus(lib.TaskSuccessStatus)
+	})
+
+	if requests != 1 {
+		t.Errorf("Expected notification on success with AlertOnSuccess, got %v requests", requests)
+	}
+}
+
 func TestGetRepoPath(t *testing.T) {
-	util.Config = &util.ConfigType{
+	util.SetConfig(&util.ConfigType{
 		TmpPath: "/tmp",
-	}
+	})
 
 	inventoryID := 1
 
@@ -109,9 +263,9 @@ func TestGetRepoPath(t *testing.T) {
 }
 
 func TestGetRepoPath_whenStartsWithSlash(t *testing.T) {
-	util.Config = &util.ConfigType{
+	util.SetConfig(&util.ConfigType{
 		TmpPath: "/tmp",
-	}
+	})
 
 	inventoryID := 1
 
@@ -240,9 +394,9 @@ func TestPopulateDetails(t *testing.T) {
 }
 
 func TestTaskGetPlaybookArgs(t *testing.T) {
-	util.Config = &util.ConfigType{
+	util.SetConfig(&util.ConfigType{
 		TmpPath: "/tmp",
-	}
+	})
 
 	inventoryID := 1
 
@@ -287,9 +441,9 @@ func TestTaskGetPlaybookArgs(t *testing.T) {
 }
 
 func TestTaskGetPlaybookArgs2(t *testing.T) {
-	util.Config = &util.ConfigType{
+	util.SetConfig(&util.ConfigType{
 		TmpPath: "/tmp",
-	}
+	})
 
 	inventoryID := 1
 
@@ -338,9 +492,9 @@ func TestTaskGetPlaybookArgs2(t *testing.T) {
 }
 
 func TestTaskGetPlaybookArgs3(t *testing.T) {
-	util.Config = &util.ConfigType{
+	util.SetConfig(&util.ConfigType{
 		TmpPath: "/tmp",
-	}
+	})
 
 	inventoryID := 1
 
@@ -424,3 +578,47 @@ func TestCheckTmpDir(t *testing.T) {
 		t.Log(err)
 	}
 }
+
+func TestNewAlert(t *testing.T) {
+	util.SetConfig(&util.ConfigType{
+		WebHost: "https://semaphore.example.com",
+	})
+
+	store := CreateBoltDB()
+	pool := CreateTaskPool(store)
+
+	version := "1.0.0"
+	userID := 42
+
+	taskRunner := TaskRunner{
+		Task: db.Task{
+			ID:      3,
+			Status:  lib.TaskFailStatus,
+			Version: &version,
+			Message: "deploy",
+			UserID:  &userID,
+		},
+		Template: db.Template{
+			ID:        2,
+			ProjectID: 1,
+			Name:      "Deploy",
+		},
+		pool: &pool,
+	}
+
+	var alert Alert
+	db.StoreSession(store, "", func() {
+		// the user doesn't exist, the alert is created without author
+		alert = taskRunner.newAlert()
+	})
+
+	if alert.TaskURL != "https://semaphore.example.com/project/1/templates/2?t=3" {
+		t.Errorf("Unexpected task URL: %v", alert.TaskURL)
+	}
+	if alert.TaskResult != "ERROR" || alert.TaskVersion != "1.0.0" || alert.TaskDescription != "- deploy" {
+		t.Errorf("Unexpected alert: %+v", alert)
+	}
+	if alert.Author != "" {
+		t.Errorf("Unexpected author: %v", alert.Author)
+	}
+}
@@ -2,24 +2,56 @@ package tasks
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/ansible-semaphore/semaphore/lib"
 	"github.com/ansible-semaphore/semaphore/util"
 	"html/template"
 	"net/http"
+	"net/mail"
 	"strconv"
 	"strings"
+	textTemplate "text/template"
+	"time"
 )
 
-const emailTemplate = "Subject: Task '{{ .Name }}' failed\r\n" +
+const emailTemplate = "Subject: Task '{{ .Name }}' {{ if eq .TaskResult \"SUCCESS\" }}succeeded{{ else }}failed{{ end }}\r\n" +
 	"From: {{ .From }}\r\n" +
+	"{{ if .To }}To: {{ .To }}\r\n{{ end }}" +
+	"{{ if .Cc }}Cc: {{ .Cc }}\r\n{{ end }}" +
 	"\r\n" +
-	"Task {{ .TaskID }} with template '{{ .Name }}' has failed!`\n" +
+	"Task {{ .TaskID }} with template '{{ .Name }}' has {{ if eq .TaskResult \"SUCCESS\" }}succeeded{{ else }}failed{{ end }}!`\n" +
 	"Task Log: {{ .TaskURL }}"
 
 const telegramTemplate = `{"chat_id": "{{ .ChatID }}","parse_mode":"HTML","text":"<code>{{ .Name }}</code>\n#{{ .TaskID }} <b>{{ .TaskResult }}</b> <code>{{ .TaskVersion }}</code> {{ .TaskDescription }}\nby {{ .Author }}\n{{ .TaskURL }}"}`
 
 const slackTemplate = `{ "attachments": [ { "title": "Task: {{ .Name }}", "title_link": "{{ .TaskURL }}", "text": "execution ID #{{ .TaskID }}, status: {{ .TaskResult }}!", "color": "{{ .Color }}", "mrkdwn_in": ["text"], "fields": [ { "title": "Author", "value": "{{ .Author }}", "short": true }] } ]}`
 
+const teamsTemplate = `{ "@type": "MessageCard", "@context": "https://schema.org/extensions", "themeColor": "{{ .Color }}", "summary": "Task: {{ .Name }}", "sections": [ { "activityTitle": "Task: {{ .Name }}", "activitySubtitle": "execution ID #{{ .TaskID }}, status: {{ .TaskResult }}!", "facts": [ { "name": "Status", "value": "{{ .TaskResult }}" }, { "name": "Version", "value": "{{ .TaskVersion }} {{ .TaskDescription }}" }, { "name": "Author", "value": "{{ .Author }}" } ] } ], "potentialAction": [ { "@type": "OpenUri", "name": "Open task", "targets": [ { "os": "default", "uri": "{{ .TaskURL }}" } ] } ]}`
+
+const discordTemplate = `{"content": "Task **{{ .Name }}** #{{ .TaskID }} **{{ .TaskResult }}** {{ .TaskVersion }} {{ .TaskDescription }}\nby {{ .Author }}\n{{ .TaskURL }}"}`
+
+// webhookTemplate is default payload of the generic webhook,
+// it is compatible with Mattermost and Rocket.Chat.
+const webhookTemplate = `{"text": "Task '{{ .Name }}' #{{ .TaskID }} {{ .TaskResult }} in {{ .Duration }} {{ .TaskVersion }}\n{{ .TaskURL }}"}`
+
+const gotifyTemplate = `{"title": "Task '{{ .Name }}' #{{ .TaskID }} {{ .TaskResult }}", "message": "{{ .TaskVersion }} {{ .TaskDescription }}\nby {{ .Author }}\n{{ .TaskURL }}", "priority": {{ .Priority }}}`
+
+var pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+const pagerDutyTemplate = `{ "routing_key": "{{ .RoutingKey }}", "event_action": "trigger", "dedup_key": "{{ .DedupKey }}", "payload": { "summary": "Task '{{ .Name }}' #{{ .TaskID }} failed", "source": "semaphore", "severity": "error", "custom_details": { "status": "{{ .TaskResult }}", "version": "{{ .TaskVersion }}", "author": "{{ .Author }}" } }, "links": [ { "href": "{{ .TaskURL }}", "text": "Task Log" } ]}`
+
+const pagerDutyResolveTemplate = `{ "routing_key": "{{ .RoutingKey }}", "event_action": "resolve", "dedup_key": "{{ .DedupKey }}" }`
+
+// alertHttpClient returns HTTP client which uses outbound proxy and CA certificates from config.
+func alertHttpClient() *http.Client {
+	transport, err := util.Config().NewHTTPTransport()
+	if err != nil {
+		util.LogError(err)
+	}
+	transport.Proxy = util.Config().GetOutboundProxy()
+	return &http.Client{Transport: transport}
+}
+
 // Alert represents an alert that will be templated and sent to the appropriate service
 type Alert struct {
 	TaskID          string
@@ -32,58 +64,122 @@ type Alert struct {
 	Author          string
 	Color           string
 	From            string
+	To              string
+	Cc              string
+	RoutingKey      string
+	DedupKey        string
+	Duration        string
+	Priority        int
+}
+
+// newAlert returns the alert of the task with the fields which are common
+// for all services.
+func (t *TaskRunner) newAlert() Alert {
+	var version string
+	if t.Task.Version != nil {
+		version = *t.Task.Version
+	} else if t.Task.BuildTaskID != nil {
+		version = "build " + strconv.Itoa(*t.Task.BuildTaskID)
+	}
+
+	var message string
+	if t.Task.Message != "" {
+		message = "- " + t.Task.Message
+	}
+
+	var author string
+	if t.Task.UserID != nil {
+		user, err := t.pool.store.GetUser(*t.Task.UserID)
+		if err != nil {
+			util.LogError(err)
+		} else {
+			author = user.Name
+		}
+	}
+
+	var duration string
+	if t.Task.Start != nil {
+		duration = time.Since(*t.Task.Start).Round(time.Second).String()
+	}
+
+	return Alert{
+		TaskID:          strconv.Itoa(t.Task.ID),
+		Name:            t.Template.Name,
+		TaskURL:         util.Config().GetWebURL() + "/project/" + strconv.Itoa(t.Template.ProjectID) + "/templates/" + strconv.Itoa(t.Template.ID) + "?t=" + strconv.Itoa(t.Task.ID),
+		TaskResult:      strings.ToUpper(string(t.Task.Status)),
+		TaskVersion:     version,
+		TaskDescription: message,
+		Author:          author,
+		Duration:        duration,
+	}
 }
 
 func (t *TaskRunner) sendMailAlert() {
-	if !util.Config.EmailAlert || !t.alert {
+	if !util.Config().EmailAlert || !t.alert {
 		return
 	}
 
-	mailHost := util.Config.EmailHost + ":" + util.Config.EmailPort
+	alert := t.newAlert()
+	alert.From = util.Config().EmailSender
 
-	var mailBuffer bytes.Buffer
-	alert := Alert{
-		TaskID: strconv.Itoa(t.Task.ID),
-		Name:   t.Template.Name,
-		TaskURL: util.Config.WebHost + "/project/" + strconv.Itoa(t.Template.ProjectID) +
-			"/templates/" + strconv.Itoa(t.Template.ID) +
-			"?t=" + strconv.Itoa(t.Task.ID),
-		From: util.Config.EmailSender,
+	// addresses of the configured recipients, they receive one mail
+	sent := make(map[string]bool)
+	to := parseMailAddresses(util.Config().EmailRecipients)
+	cc := parseMailAddresses(util.Config().EmailCC)
+	listRecipients := append(append([]string{}, to...), cc...)
+	for _, addr := range listRecipients {
+		sent[strings.ToLower(addr)] = true
 	}
-	tpl := template.New("mail body template")
-	tpl, err := tpl.Parse(emailTemplate)
-	util.LogError(err)
 
-	t.panicOnError(tpl.Execute(&mailBuffer, alert), "Can't generate alert template!")
+	if len(listRecipients) > 0 {
+		listAlert := alert
+		listAlert.To = strings.Join(to, ", ")
+		listAlert.Cc = strings.Join(cc, ", ")
+
+		if err := sendMailMessage(listAlert, listRecipients); err != nil {
+			util.LogError(err)
+		}
+	}
 
 	for _, user := range t.users {
 		userObj, err2 := t.pool.store.GetUser(user)
 
-		if !userObj.Alert {
+		if err2 != nil {
+			util.LogError(err2)
 			continue
 		}
 
-		if err2 != nil {
-			util.LogError(err2)
+		if !userObj.Alert {
 			continue
 		}
 
-		if util.Config.EmailSecure {
-			err2 = util.SendSecureMail(util.Config.EmailHost, util.Config.EmailPort,
-				util.Config.EmailSender, util.Config.EmailUsername, util.Config.EmailPassword,
-				userObj.Email, mailBuffer)
-		} else {
-			err2 = util.SendMail(mailHost, util.Config.EmailSender, userObj.Email, mailBuffer)
+		if sent[strings.ToLower(userObj.Email)] {
+			// the user has already received the alert as configured recipient
+			continue
 		}
 
-		if err2 != nil {
+		if err2 = sendMailMessage(alert, []string{userObj.Email}); err2 != nil {
 			util.LogError(err2)
 		}
 	}
 }
 
+// parseMailAddresses returns bare email addresses of the recipients,
+// invalid addresses are logged and skipped.
+func parseMailAddresses(recipients []string) (addresses []string) {
+	for _, recipient := range recipients {
+		addr, err := mail.ParseAddress(recipient)
+		if err != nil {
+			util.LogError(err)
+			continue
+		}
+		addresses = append(addresses, addr.Address)
+	}
+	return
+}
+
 func (t *TaskRunner) sendTelegramAlert() {
-	if !util.Config.TelegramAlert || !t.alert {
+	if !util.Config().TelegramAlert || !t.alert {
 		return
 	}
 
@@ -91,79 +187,141 @@ func (t *TaskRunner) sendTelegramAlert() {
 		return
 	}
 
-	chatID := util.Config.TelegramChat
+	chatID := util.Config().TelegramChat
 	if t.alertChat != nil && *t.alertChat != "" {
 		chatID = *t.alertChat
 	}
 
-	if chatID == "" {
+	// chat can contain comma-separated list of chat IDs
+	var chatIDs []string
+	for _, id := range strings.Split(chatID, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			chatIDs = append(chatIDs, id)
+		}
+	}
+
+	if len(chatIDs) == 0 {
 		return
 	}
 
-	var telegramBuffer bytes.Buffer
+	alert := t.newAlert()
+	if t.Task.Version == nil && t.Task.BuildTaskID != nil {
+		if buildVer := t.Task.GetIncomingVersion(t.pool.store); buildVer != nil {
+			alert.TaskVersion = *buildVer
+		}
+	}
 
-	var version string
-	if t.Task.Version != nil {
-		version = *t.Task.Version
-	} else if t.Task.BuildTaskID != nil {
-		buildVer := t.Task.GetIncomingVersion(t.pool.store)
-		if buildVer != nil {
-			version = *buildVer
+	for _, id := range chatIDs {
+		alert.ChatID = id
+
+		if err := sendTelegramMessage(util.Config().TelegramToken, alert); err != nil {
+			t.Log("Can't send telegram alert to chat " + id + "! Error: " + err.Error())
 		}
+	}
+}
+
+func (t *TaskRunner) sendSlackAlert() {
+	if !util.Config().SlackAlert || !t.alert {
+		return
+	}
+
+	if t.Template.SuppressSuccessAlerts && t.Task.Status == lib.TaskSuccessStatus {
+		return
+	}
+
+	var color string
+	if t.Task.Status == lib.TaskSuccessStatus {
+		color = "good"
+	} else if t.Task.Status == lib.TaskFailStatus {
+		color = "bad"
+	} else if t.Task.Status == lib.TaskRunningStatus {
+		color = "#333CFF"
+	} else if t.Task.Status == lib.TaskWaitingStatus {
+		color = "#FFFC33"
+	} else if t.Task.Status == lib.TaskStoppingStatus {
+		color = "#BEBEBE"
+	} else if t.Task.Status == lib.TaskStoppedStatus {
+		color = "#5B5B5B"
+	}
+
+	alert := t.newAlert()
+	alert.Color = color
+
+	if err := sendSlackMessage(util.Config().SlackUrl, alert); err != nil {
+		t.Log("Can't send slack alert! Error: " + err.Error())
+	}
+}
+
+func (t *TaskRunner) sendTeamsAlert() {
+	if !util.Config().TeamsAlert || !t.alert {
+		return
+	}
+
+	if t.Template.SuppressSuccessAlerts && t.Task.Status == lib.TaskSuccessStatus {
+		return
+	}
+
+	var color string
+	if t.Task.Status == lib.TaskSuccessStatus {
+		color = "2EB886"
+	} else if t.Task.Status == lib.TaskFailStatus {
+		color = "E01E5A"
 	} else {
-		version = ""
+		color = "BEBEBE"
 	}
 
-	var message string
-	if t.Task.Message != "" {
-		message = "- " + t.Task.Message
+	alert := t.newAlert()
+	alert.Color = color
+
+	if err := sendTeamsMessage(util.Config().TeamsUrl, alert); err != nil {
+		t.Log("Can't send teams alert! Error: " + err.Error())
 	}
+}
 
-	var author string
-	if t.Task.UserID != nil {
-		user, err := t.pool.store.GetUser(*t.Task.UserID)
-		if err != nil {
-			panic(err)
-		}
-		author = user.Name
+// sendPagerDutyAlert triggers PagerDuty incident for the failed task.
+// Dedup key is derived from the template, so repeated failures of
+// the template are grouped into one incident.
+func (t *TaskRunner) sendPagerDutyAlert() {
+	if !util.Config().PagerDutyAlert || !t.alert {
+		return
 	}
 
-	alert := Alert{
-		TaskID:          strconv.Itoa(t.Task.ID),
-		Name:            t.Template.Name,
-		TaskURL:         util.Config.WebHost + "/project/" + strconv.Itoa(t.Template.ProjectID) + "/templates/" + strconv.Itoa(t.Template.ID) + "?t=" + strconv.Itoa(t.Task.ID),
-		ChatID:          chatID,
-		TaskResult:      strings.ToUpper(string(t.Task.Status)),
-		TaskVersion:     version,
-		TaskDescription: message,
-		Author:          author,
+	alert := t.newAlert()
+	alert.RoutingKey = util.Config().PagerDutyRoutingKey
+	alert.DedupKey = "semaphore-template-" + strconv.Itoa(t.Template.ID)
+
+	if err := sendPagerDutyEvent(alert); err != nil {
+		t.Log("Can't send pagerduty alert! Error: " + err.Error())
 	}
+}
 
-	tpl := template.New("telegram body template")
+func (t *TaskRunner) sendDiscordAlert() {
+	if !util.Config().DiscordAlert || !t.alert {
+		return
+	}
 
-	tpl, err := tpl.Parse(telegramTemplate)
-	if err != nil {
-		t.Log("Can't parse telegram template!")
-		panic(err)
+	alert := t.newAlert()
+
+	if err := sendDiscordMessage(util.Config().DiscordUrl, alert); err != nil {
+		t.Log("Can't send discord alert! Error: " + err.Error())
 	}
+}
 
-	err = tpl.Execute(&telegramBuffer, alert)
-	if err != nil {
-		t.Log("Can't generate alert template!")
-		panic(err)
+// sendWebhookAlert sends the alert to the generic webhook, see sendWebhookMessage.
+func (t *TaskRunner) sendWebhookAlert() {
+	if !util.Config().WebhookAlert || !t.alert {
+		return
 	}
 
-	resp, err := http.Post("https://api.telegram.org/bot"+util.Config.TelegramToken+"/sendMessage", "application/json", &telegramBuffer)
+	alert := t.newAlert()
 
-	if err != nil {
-		t.Log("Can't send telegram alert! Error: " + err.Error())
-	} else if resp.StatusCode != 200 {
-		t.Log("Can't send telegram alert! Response code: " + strconv.Itoa(resp.StatusCode))
+	if err := sendWebhookMessage(util.Config().WebhookUrl, alert); err != nil {
+		t.Log("Can't send webhook alert! Error: " + err.Error())
 	}
 }
 
-func (t *TaskRunner) sendSlackAlert() {
-	if !util.Config.SlackAlert || !t.alert {
+func (t *TaskRunner) sendGotifyAlert() {
+	if !util.Config().GotifyAlert || !t.alert {
 		return
 	}
 
@@ -171,76 +329,151 @@ func (t *TaskRunner) sendSlackAlert() {
 		return
 	}
 
-	slackUrl := util.Config.SlackUrl
+	// failed tasks are shown as high priority notifications
+	priority := 4
+	if t.Task.Status == lib.TaskFailStatus {
+		priority = 8
+	}
 
-	var slackBuffer bytes.Buffer
+	alert := t.newAlert()
+	alert.Priority = priority
 
-	var version string
-	if t.Task.Version != nil {
-		version = *t.Task.Version
-	} else if t.Task.BuildTaskID != nil {
-		version = "build " + strconv.Itoa(*t.Task.BuildTaskID)
-	} else {
-		version = ""
+	if err := sendGotifyMessage(util.Config().GotifyUrl, util.Config().GotifyToken, alert); err != nil {
+		t.Log("Can't send gotify alert! Error: " + err.Error())
 	}
+}
 
-	var message string
-	if t.Task.Message != "" {
-		message = "- " + t.Task.Message
+// renderAlert renders the alert by HTML template tpl, values are escaped.
+func renderAlert(name string, tpl string, alert Alert) (*bytes.Buffer, error) {
+	t, err := template.New(name).Parse(tpl)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse %v: %v", name, err)
 	}
 
-	var author string
-	if t.Task.UserID != nil {
-		user, err := t.pool.store.GetUser(*t.Task.UserID)
-		if err != nil {
-			panic(err)
+	var buf bytes.Buffer
+	if err = t.Execute(&buf, alert); err != nil {
+		return nil, fmt.Errorf("can't generate %v: %v", name, err)
+	}
+
+	return &buf, nil
+}
+
+// doAlertRequest sends the request to alert service and checks that
+// the response has one of the expected status codes, any 2xx code
+// is accepted if they are not passed.
+func doAlertRequest(req *http.Request, statusCodes ...int) error {
+	resp, err := alertHttpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+
+	if len(statusCodes) == 0 && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	for _, code := range statusCodes {
+		if resp.StatusCode == code {
+			return nil
 		}
-		author = user.Name
 	}
 
-	var color string
-	if t.Task.Status == lib.TaskSuccessStatus {
-		color = "good"
-	} else if t.Task.Status == lib.TaskFailStatus {
-		color = "bad"
-	} else if t.Task.Status == lib.TaskRunningStatus {
-		color = "#333CFF"
-	} else if t.Task.Status == lib.TaskWaitingStatus {
-		color = "#FFFC33"
-	} else if t.Task.Status == lib.TaskStoppingStatus {
-		color = "#BEBEBE"
-	} else if t.Task.Status == lib.TaskStoppedStatus {
-		color = "#5B5B5B"
+	return fmt.Errorf("unexpected response code %d", resp.StatusCode)
+}
+
+// postAlert posts the alert rendered by template tpl as JSON to url.
+func postAlert(url string, name string, tpl string, alert Alert, statusCodes ...int) error {
+	body, err := renderAlert(name, tpl, alert)
+	if err != nil {
+		return err
 	}
-	alert := Alert{
-		TaskID:          strconv.Itoa(t.Task.ID),
-		Name:            t.Template.Name,
-		TaskURL:         util.Config.WebHost + "/project/" + strconv.Itoa(t.Template.ProjectID) + "/templates/" + strconv.Itoa(t.Template.ID) + "?t=" + strconv.Itoa(t.Task.ID),
-		TaskResult:      strings.ToUpper(string(t.Task.Status)),
-		TaskVersion:     version,
-		TaskDescription: message,
-		Author:          author,
-		Color:           color,
+
+	req, err := http.NewRequest("POST", url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return doAlertRequest(req, statusCodes...)
+}
+
+func sendMailMessage(alert Alert, recipients []string) error {
+	body, err := renderAlert("mail body template", emailTemplate, alert)
+	if err != nil {
+		return err
 	}
 
-	tpl := template.New("slack body template")
+	return util.SendMail(util.Config().EmailHost, util.Config().EmailPort, util.Config().GetEmailSecurity(),
+		util.Config().EmailSender, util.Config().EmailUsername, util.Config().EmailPassword,
+		recipients, *body)
+}
+
+func sendTelegramMessage(token string, alert Alert) error {
+	return postAlert("https://api.telegram.org/bot"+token+"/sendMessage",
+		"telegram body template", telegramTemplate, alert, 200)
+}
+
+func sendSlackMessage(url string, alert Alert) error {
+	return postAlert(url, "slack body template", slackTemplate, alert, 200)
+}
+
+func sendTeamsMessage(url string, alert Alert) error {
+	return postAlert(url, "teams body template", teamsTemplate, alert, 200)
+}
+
+func sendDiscordMessage(url string, alert Alert) error {
+	return postAlert(url, "discord body template", discordTemplate, alert, 200, 204)
+}
+
+func sendPagerDutyEvent(alert Alert) error {
+	return postAlert(pagerDutyEventsURL, "pagerduty body template", pagerDutyTemplate, alert, 202)
+}
 
-	tpl, err := tpl.Parse(slackTemplate)
+// resolvePagerDutyEvent resolves PagerDuty incident with dedup key of the alert.
+func resolvePagerDutyEvent(alert Alert) error {
+	return postAlert(pagerDutyEventsURL, "pagerduty resolve template", pagerDutyResolveTemplate, alert, 202)
+}
+
+// sendWebhookMessage posts payload rendered from the configured template to
+// the generic webhook. Unlike other alerts, payload template is provided by
+// user and is rendered by text/template to avoid HTML escaping.
+func sendWebhookMessage(url string, alert Alert) error {
+	payloadTemplate := util.Config().WebhookPayloadTemplate
+	if payloadTemplate == "" {
+		payloadTemplate = webhookTemplate
+	}
+
+	tpl, err := textTemplate.New("webhook body template").Parse(payloadTemplate)
 	if err != nil {
-		t.Log("Can't parse slack template!")
-		panic(err)
+		return fmt.Errorf("can't parse webhook body template: %v", err)
 	}
 
-	err = tpl.Execute(&slackBuffer, alert)
+	var body bytes.Buffer
+	if err = tpl.Execute(&body, alert); err != nil {
+		return fmt.Errorf("can't generate webhook body template: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", url, &body)
 	if err != nil {
-		t.Log("Can't generate alert template!")
-		panic(err)
+		return err
 	}
-	resp, err := http.Post(slackUrl, "application/json", &slackBuffer)
+	req.Header.Set("Content-Type", "application/json")
 
+	return doAlertRequest(req)
+}
+
+func sendGotifyMessage(url string, token string, alert Alert) error {
+	body, err := renderAlert("gotify body template", gotifyTemplate, alert)
 	if err != nil {
-		t.Log("Can't send slack alert! Error: " + err.Error())
-	} else if resp.StatusCode != 200 {
-		t.Log("Can't send slack alert! Response code: " + strconv.Itoa(resp.StatusCode))
+		return err
+	}
+
+	req, err := http.NewRequest("POST", strings.TrimRight(url, "/")+"/message", body)
+	if err != nil {
+		return err
 	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gotify-Key", token)
+
+	return doAlertRequest(req, 200)
 }
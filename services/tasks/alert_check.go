@@ -0,0 +1,119 @@
+package tasks
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ansible-semaphore/semaphore/util"
+)
+
+// AlertResult is result of sending the test alert through one backend.
+type AlertResult struct {
+	Backend string
+	Err     error
+}
+
+// testAlert returns sample alert which is sent instead of the alert of a real task.
+func testAlert() Alert {
+	return Alert{
+		TaskID:          "0",
+		Name:            "Semaphore test alert",
+		TaskURL:         util.Config().GetWebURL(),
+		TaskResult:      "SUCCESS",
+		TaskDescription: "- alerts are configured correctly",
+		Author:          "semaphore",
+		Color:           "good",
+		From:            util.Config().EmailSender,
+		DedupKey:        "semaphore-test-alert",
+		RoutingKey:      util.Config().PagerDutyRoutingKey,
+		Priority:        4,
+	}
+}
+
+// SendTestAlerts sends the test alert through every enabled alert backend
+// and returns results in the order of backends.
+func SendTestAlerts() (results []AlertResult) {
+	alert := testAlert()
+
+	send := func(backend string, enabled bool, fn func() error) {
+		if enabled {
+			results = append(results, AlertResult{Backend: backend, Err: fn()})
+		}
+	}
+
+	send("email", util.Config().EmailAlert, func() error {
+		alert := alert
+		recipients := parseMailAddresses(append(append([]string{}, util.Config().EmailRecipients...), util.Config().EmailCC...))
+		if len(recipients) == 0 {
+			// nobody else is known without task users
+			recipients = []string{util.Config().EmailSender}
+		}
+		alert.To = strings.Join(recipients, ", ")
+		return sendMailMessage(alert, recipients)
+	})
+
+	send("telegram", util.Config().TelegramAlert, func() error {
+		for _, id := range strings.Split(util.Config().TelegramChat, ",") {
+			if id = strings.TrimSpace(id); id == "" {
+				continue
+			}
+			alert := alert
+			alert.ChatID = id
+			if err := sendTelegramMessage(util.Config().TelegramToken, alert); err != nil {
+				return fmt.Errorf("chat %v: %v", id, err)
+			}
+		}
+		return nil
+	})
+
+	send("slack", util.Config().SlackAlert, func() error { return sendSlackMessage(util.Config().SlackUrl, alert) })
+	send("teams", util.Config().TeamsAlert, func() error { return sendTeamsMessage(util.Config().TeamsUrl, alert) })
+	send("discord", util.Config().DiscordAlert, func() error { return sendDiscordMessage(util.Config().DiscordUrl, alert) })
+	send("webhook", util.Config().WebhookAlert, func() error { return sendWebhookMessage(util.Config().WebhookUrl, alert) })
+	send("gotify", util.Config().GotifyAlert, func() error { return sendGotifyMessage(util.Config().GotifyUrl, util.Config().GotifyToken, alert) })
+	send("pagerduty", util.Config().PagerDutyAlert, func() error { return sendPagerDutyTestEvent(alert) })
+
+	for i, rawURL := range util.Config().NotificationURLs {
+		send("notification URL #"+strconv.Itoa(i), true, func() error {
+			n, err := util.ParseNotificationURL(rawURL)
+			if err != nil {
+				return err
+			}
+			if n.Backend == util.NotificationPagerDuty {
+				alert := alert
+				alert.RoutingKey = n.Token
+				return sendPagerDutyTestEvent(alert)
+			}
+			return sendNotification(n, alert)
+		})
+	}
+
+	return
+}
+
+// sendPagerDutyTestEvent triggers PagerDuty incident for the test alert and
+// resolves it at once, so the test doesn't leave an open incident.
+func sendPagerDutyTestEvent(alert Alert) error {
+	if err := sendPagerDutyEvent(alert); err != nil {
+		return err
+	}
+	return resolvePagerDutyEvent(alert)
+}
+
+// SendTestAlert sends the test alert through every enabled alert backend
+// and returns an error naming the backends which failed.
+func SendTestAlert() error {
+	var failed []string
+	for _, res := range SendTestAlerts() {
+		if res.Err != nil {
+			failed = append(failed, res.Backend+": "+res.Err.Error())
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("can't send test alert through %v", strings.Join(failed, "; "))
+	}
+
+	return nil
+}
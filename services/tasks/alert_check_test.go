@@ -0,0 +1,58 @@
+package tasks
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ansible-semaphore/semaphore/util"
+)
+
+func TestSendTestAlertsResolvesPagerDutyIncident(t *testing.T) {
+	var events []map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Error(err)
+		}
+		events = append(events, event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	eventsURL := pagerDutyEventsURL
+	pagerDutyEventsURL = server.URL
+	defer func() { pagerDutyEventsURL = eventsURL }()
+
+	util.SetConfig(&util.ConfigType{
+		PagerDutyAlert:      true,
+		PagerDutyRoutingKey: "key1",
+		NotificationURLs:    []string{"pagerduty://key2"},
+	})
+
+	if err := SendTestAlert(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct{ key, action string }{
+		{"key1", "trigger"},
+		{"key1", "resolve"},
+		{"key2", "trigger"},
+		{"key2", "resolve"},
+	}
+
+	if len(events) != len(expected) {
+		t.Fatalf("Unexpected events count: %v", len(events))
+	}
+
+	for i, e := range expected {
+		if events[i]["routing_key"] != e.key || events[i]["event_action"] != e.action {
+			t.Errorf("Unexpected event #%v: %v", i, events[i])
+		}
+		if events[i]["dedup_key"] != "semaphore-test-alert" {
+			t.Errorf("Unexpected dedup key of event #%v: %v", i, events[i]["dedup_key"])
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package tasks
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ansible-semaphore/semaphore/lib"
+	"github.com/ansible-semaphore/semaphore/util"
+)
+
+// sendNotificationAlerts sends the alert to each of NotificationURLs.
+// It is called on success only if AlertOnSuccess is enabled.
+// PagerDuty incidents are triggered only for failed tasks.
+func (t *TaskRunner) sendNotificationAlerts() {
+	if len(util.Config().NotificationURLs) == 0 || !t.alert {
+		return
+	}
+
+	alert := t.newAlert()
+	alert.Color = "good"
+	if t.Task.Status == lib.TaskFailStatus {
+		alert.Color = "bad"
+	}
+	alert.DedupKey = "semaphore-template-" + strconv.Itoa(t.Template.ID)
+	alert.Priority = 5
+
+	for i, rawURL := range util.Config().NotificationURLs {
+		n, err := util.ParseNotificationURL(rawURL)
+		if err == nil && n.Backend == util.NotificationPagerDuty && t.Task.Status != lib.TaskFailStatus {
+			continue
+		}
+
+		if err == nil {
+			err = sendNotification(n, alert)
+		}
+
+		if err != nil {
+			// URL isn't logged, it contains credentials
+			t.Log("Can't send alert to notification URL #" + strconv.Itoa(i) + "! Error: " + err.Error())
+		}
+	}
+}
+
+// sendNotification sends the alert through the backend of the notification URL.
+func sendNotification(n util.NotificationURL, alert Alert) error {
+	switch n.Backend {
+	case util.NotificationTelegram:
+		for _, id := range n.Targets {
+			alert.ChatID = id
+			if err := sendTelegramMessage(n.Token, alert); err != nil {
+				return fmt.Errorf("chat %v: %v", id, err)
+			}
+		}
+		return nil
+	case util.NotificationSlack:
+		return sendSlackMessage(n.Endpoint, alert)
+	case util.NotificationDiscord:
+		return sendDiscordMessage(n.Endpoint, alert)
+	case util.NotificationWebhook:
+		return sendWebhookMessage(n.Endpoint, alert)
+	case util.NotificationGotify:
+		return sendGotifyMessage(n.Endpoint, n.Token, alert)
+	case util.NotificationPagerDuty:
+		alert.RoutingKey = n.Token
+		return sendPagerDutyEvent(alert)
+	case util.NotificationEmail:
+		alert.From = n.From
+		alert.To = strings.Join(n.Targets, ", ")
+		body, err := renderAlert("mail body template", emailTemplate, alert)
+		if err != nil {
+			return err
+		}
+		return util.SendMail(n.Host, n.Port, n.Security, n.From, n.Username, n.Password, n.Targets, *body)
+	default:
+		return fmt.Errorf("unsupported notification backend: %v", n.Backend)
+	}
+}
@@ -0,0 +1,78 @@
+package util
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// enumRuleRE matches validation rules which are lists of allowed values.
+var enumRuleRE = regexp.MustCompile(`^\^\(([\w|-]*)\)\$$`)
+
+// ConfigSchema returns JSON Schema of the config file. It is generated
+// from json tags of ConfigType, rules of the form `^(a|b)$` become enums.
+func ConfigSchema() map[string]interface{} {
+	schema := typeSchema(reflect.TypeOf(ConfigType{}), "")
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "Semaphore config"
+	return schema
+}
+
+func typeSchema(t reflect.Type, rule string) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem(), rule)
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		schema := map[string]interface{}{"type": "string"}
+		if m := enumRuleRE.FindStringSubmatch(rule); m != nil {
+			var values []string
+			for _, value := range strings.Split(m[1], "|") {
+				if value != "" {
+					values = append(values, value)
+				}
+			}
+			if strings.HasPrefix(m[1], "|") || strings.Contains(m[1], "||") {
+				values = append(values, "")
+			}
+			schema["enum"] = values
+		}
+		return schema
+	case reflect.Slice:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": typeSchema(t.Elem(), ""),
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": typeSchema(t.Elem(), ""),
+		}
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "-" || name == "" {
+				continue
+			}
+
+			properties[name] = typeSchema(field.Type, field.Tag.Get("rule"))
+		}
+		return map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+		}
+	}
+
+	return map[string]interface{}{}
+}
@@ -2,34 +2,77 @@ package util
 
 import (
 	"bytes"
+	"crypto/tls"
+	"errors"
 	log "github.com/Sirupsen/logrus"
-	"io"
+	"net"
 	"net/smtp"
+	"strings"
 )
 
-// SendMail dispatches a mail using smtp
-func SendMail(emailHost, mailSender, mailRecipient string, mail bytes.Buffer) error {
-	c, err := smtp.Dial(emailHost)
-	if err != nil {
-		return err
+const (
+	MailSecurityNone     = "none"
+	MailSecurityStartTLS = "starttls"
+	MailSecurityTLS      = "tls"
+)
+
+// SendMail dispatches a mail using smtp. Connection is secured according
+// to security (one of MailSecurity* constants). SMTP AUTH (PLAIN or LOGIN)
+// is used if mailUsername is set. The mail is delivered to all mailRecipients
+// in one transaction.
+func SendMail(emailHost, emailPort, security, mailSender, mailUsername, mailPassword string, mailRecipients []string, mail bytes.Buffer) error {
+	addr := net.JoinHostPort(emailHost, emailPort)
+	tlsConfig := &tls.Config{ServerName: emailHost}
+
+	var c *smtp.Client
+
+	if security == MailSecurityTLS {
+		conn, err := tls.Dial("tcp", addr, tlsConfig)
+		if err != nil {
+			return err
+		}
+		c, err = smtp.NewClient(conn, emailHost)
+		if err != nil {
+			return err
+		}
+	} else {
+		var err error
+		c, err = smtp.Dial(addr)
+		if err != nil {
+			return err
+		}
 	}
 
 	defer func(c *smtp.Client) {
-		err = c.Close()
+		err := c.Close()
 		if err != nil {
 			log.Error(err)
 		}
 	}(c)
 
-	// Set the sender and recipient.
-	err = c.Mail(mailSender)
-	if err != nil {
-		return err
+	if security == MailSecurityStartTLS {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return err
+		}
 	}
-	err = c.Rcpt(mailRecipient)
+
+	if mailUsername != "" {
+		if err := c.Auth(getMailAuth(c, emailHost, mailUsername, mailPassword)); err != nil {
+			return err
+		}
+	}
+
+	// Set the sender and recipients.
+	err := c.Mail(mailSender)
 	if err != nil {
 		return err
 	}
+	for _, recipient := range mailRecipients {
+		err = c.Rcpt(recipient)
+		if err != nil {
+			return err
+		}
+	}
 
 	// Send the email body.
 	wc, err := c.Data()
@@ -37,31 +80,55 @@ func SendMail(emailHost, mailSender, mailRecipient string, mail bytes.Buffer) er
 		return err
 	}
 
-	defer func(wc io.WriteCloser) {
-		err = wc.Close()
-		if err != nil {
-			log.Error(err)
-		}
-	}(wc)
 	_, err = mail.WriteTo(wc)
-	return err
-}
+	if err != nil {
+		_ = wc.Close()
+		return err
+	}
 
-// SendSecureMail dispatches a mail using smtp with authentication and StartTLS
-func SendSecureMail(emailHost, emailPort, mailSender, mailUsername, mailPassword, mailRecipient string, mail bytes.Buffer) error {
+	return wc.Close()
+}
 
-	// Receiver email address.
-	to := []string{
-		mailRecipient,
+// getMailAuth returns PLAIN auth if the server supports it, otherwise LOGIN auth.
+func getMailAuth(c *smtp.Client, emailHost, mailUsername, mailPassword string) smtp.Auth {
+	if ok, mechanisms := c.Extension("AUTH"); ok {
+		supported := strings.Fields(strings.ToUpper(mechanisms))
+		hasPlain := false
+		hasLogin := false
+		for _, m := range supported {
+			hasPlain = hasPlain || m == "PLAIN"
+			hasLogin = hasLogin || m == "LOGIN"
+		}
+		if hasLogin && !hasPlain {
+			return &loginAuth{username: mailUsername, password: mailPassword}
+		}
 	}
 
-	// Authentication.
-	auth := smtp.PlainAuth("", mailUsername, mailPassword, emailHost)
+	return smtp.PlainAuth("", mailUsername, mailPassword, emailHost)
+}
 
-	// Sending email.
-	err := smtp.SendMail(emailHost+":"+emailPort, auth, mailSender, to, mail.Bytes())
-	if err != nil {
-		log.Error(err)
+// loginAuth implements LOGIN authentication mechanism
+// which is not supported by net/smtp.
+type loginAuth struct {
+	username string
+	password string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	return "LOGIN", []byte{}, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, errors.New("unexpected server challenge: " + string(fromServer))
 	}
-	return err
 }
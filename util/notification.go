@@ -0,0 +1,227 @@
+package util
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+)
+
+// Backends of notification URLs.
+const (
+	NotificationTelegram  = "telegram"
+	NotificationSlack     = "slack"
+	NotificationDiscord   = "discord"
+	NotificationWebhook   = "webhook"
+	NotificationGotify    = "gotify"
+	NotificationPagerDuty = "pagerduty"
+	NotificationEmail     = "email"
+)
+
+// NotificationURL is a notification target parsed from Apprise-like URL.
+// Schemes of the URLs select the backend:
+//
+//	tgram://{bot_token}/{chat_id}[/{chat_id}...]
+//	slack://{token_a}/{token_b}/{token_c}
+//	discord://{webhook_id}/{webhook_token}
+//	json://{host}[:port][/path], jsons:// for HTTPS
+//	gotify://{host}[:port][/path]/{app_token}, gotifys:// for HTTPS
+//	pagerduty://{routing_key}
+//	mailto://[user:pass@]{host}[:port]?to={email}[,{email}...][&from={email}][&security=none|starttls|tls],
+//	mailtos:// uses STARTTLS by default
+type NotificationURL struct {
+	Backend string
+	// Endpoint is URL the alert is posted to, or address of SMTP server.
+	// It is empty for PagerDuty which has the single endpoint.
+	Endpoint string
+	// Token is Telegram bot token, Gotify application token or PagerDuty routing key.
+	Token string
+	// Targets are Telegram chat IDs or email recipients.
+	Targets []string
+
+	// mail settings
+	Host     string
+	Port     string
+	Username string
+	Password string
+	Security string
+	From     string
+}
+
+// pathSegments returns non-empty host and path segments of the URL.
+func pathSegments(u *url.URL) (segments []string) {
+	for _, s := range strings.Split(u.Host+"/"+u.Path, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return
+}
+
+// ParseNotificationURL parses notification URL and checks that
+// it contains all the parameters of its backend.
+func ParseNotificationURL(rawURL string) (n NotificationURL, err error) {
+	scheme := strings.SplitN(rawURL, "://", 2)[0]
+
+	if strings.ToLower(scheme) == "tgram" {
+		// bot token contains `:`, so it can't be parsed as host
+		segments := strings.Split(strings.TrimPrefix(rawURL[len(scheme):], "://"), "/")
+		n.Backend = NotificationTelegram
+		n.Token = segments[0]
+		for _, s := range segments[1:] {
+			if s != "" {
+				n.Targets = append(n.Targets, s)
+			}
+		}
+		if n.Token == "" || len(n.Targets) == 0 {
+			err = fmt.Errorf("bot token and chat ID are required")
+			return
+		}
+		n.Endpoint = "https://api.telegram.org/bot" + n.Token + "/sendMessage"
+		return
+	}
+
+	u, err := url.Parse(rawURL)
+	if urlErr, ok := err.(*url.Error); ok {
+		// error of url.Parse contains the URL with credentials
+		err = urlErr.Err
+	}
+	if err != nil {
+		return
+	}
+
+	segments := pathSegments(u)
+
+	switch u.Scheme {
+	case "slack":
+		if len(segments) != 3 {
+			err = fmt.Errorf("three tokens of Slack webhook are required")
+			return
+		}
+		n.Backend = NotificationSlack
+		n.Endpoint = "https://hooks.slack.com/services/" + strings.Join(segments, "/")
+	case "discord":
+		if len(segments) != 2 {
+			err = fmt.Errorf("webhook ID and token are required")
+			return
+		}
+		n.Backend = NotificationDiscord
+		n.Endpoint = "https://discord.com/api/webhooks/" + strings.Join(segments, "/")
+	case "json", "jsons":
+		if u.Host == "" {
+			err = fmt.Errorf("host is required")
+			return
+		}
+		endpoint := *u
+		endpoint.Scheme = "http"
+		if u.Scheme == "jsons" {
+			endpoint.Scheme = "https"
+		}
+		n.Backend = NotificationWebhook
+		n.Endpoint = endpoint.String()
+	case "gotify", "gotifys":
+		if u.Host == "" || len(segments) < 2 {
+			err = fmt.Errorf("host and application token are required")
+			return
+		}
+		scheme := "http"
+		if u.Scheme == "gotifys" {
+			scheme = "https"
+		}
+		n.Backend = NotificationGotify
+		n.Token = segments[len(segments)-1]
+		n.Endpoint = scheme + "://" + strings.Join(segments[:len(segments)-1], "/")
+	case "pagerduty":
+		if len(segments) != 1 {
+			err = fmt.Errorf("routing key is required")
+			return
+		}
+		n.Backend = NotificationPagerDuty
+		n.Token = segments[0]
+	case "mailto", "mailtos":
+		err = parseMailNotificationURL(u, &n)
+	default:
+		err = fmt.Errorf("unsupported scheme: %v", u.Scheme)
+	}
+
+	return
+}
+
+func parseMailNotificationURL(u *url.URL, n *NotificationURL) error {
+	query := u.Query()
+
+	n.Backend = NotificationEmail
+	n.Host = u.Hostname()
+	n.Port = u.Port()
+	n.From = query.Get("from")
+	n.Security = query.Get("security")
+
+	if n.Host == "" {
+		return fmt.Errorf("mail server host is required")
+	}
+
+	if u.User != nil {
+		n.Username = u.User.Username()
+		n.Password, _ = u.User.Password()
+	}
+
+	if n.Security == "" {
+		n.Security = MailSecurityNone
+		if u.Scheme == "mailtos" {
+			n.Security = MailSecurityStartTLS
+		}
+	}
+
+	switch n.Security {
+	case MailSecurityNone, MailSecurityStartTLS, MailSecurityTLS:
+	default:
+		return fmt.Errorf("unsupported mail security: %v", n.Security)
+	}
+
+	if n.Port == "" {
+		n.Port = "25"
+		if n.Security == MailSecurityTLS {
+			n.Port = "465"
+		} else if n.Security == MailSecurityStartTLS {
+			n.Port = "587"
+		}
+	}
+
+	for _, to := range strings.Split(query.Get("to"), ",") {
+		if to = strings.TrimSpace(to); to == "" {
+			continue
+		}
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("recipient is not valid email: %v", to)
+		}
+		n.Targets = append(n.Targets, to)
+	}
+
+	if len(n.Targets) == 0 {
+		return fmt.Errorf("recipients are required")
+	}
+
+	if n.From == "" {
+		if n.Username != "" && strings.Contains(n.Username, "@") {
+			n.From = n.Username
+		} else {
+			n.From = "semaphore@" + n.Host
+		}
+	}
+
+	n.Endpoint = u.Host
+	return nil
+}
+
+func validateNotificationURLs(conf *ConfigType) error {
+	var errs ConfigErrors
+
+	for i, rawURL := range conf.NotificationURLs {
+		// URL isn't printed, it contains credentials
+		if _, err := ParseNotificationURL(rawURL); err != nil {
+			errs.add(fmt.Errorf("value of field 'NotificationURLs[%d]' is not valid notification URL: %v", i, err))
+		}
+	}
+
+	return errs.errOrNil()
+}
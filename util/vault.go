@@ -0,0 +1,190 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// vaultRefPrefix marks config values which are keys of the secret
+// stored in HashiCorp Vault, for example `vault:db_password`.
+const vaultRefPrefix = "vault:"
+
+// VaultConfig is connection to HashiCorp Vault which stores secrets
+// referenced by config values. Token or AppRole (RoleID and SecretID)
+// is used for authentication.
+type VaultConfig struct {
+	Address    string `json:"address" env:"SEMAPHORE_VAULT_ADDR"`
+	Token      string `json:"token" env:"SEMAPHORE_VAULT_TOKEN"`
+	RoleID     string `json:"role_id,omitempty" env:"SEMAPHORE_VAULT_ROLE_ID"`
+	SecretID   string `json:"secret_id,omitempty" env:"SEMAPHORE_VAULT_SECRET_ID"`
+	SecretPath string `json:"secret_path" env:"SEMAPHORE_VAULT_SECRET_PATH"`
+}
+
+type vaultClient struct {
+	conf   VaultConfig
+	client *http.Client
+	token  string
+	secret map[string]interface{}
+}
+
+func (v *vaultClient) request(method string, path string, body interface{}, res interface{}) error {
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, strings.TrimRight(v.conf.Address, "/")+"/v1/"+strings.TrimLeft(path, "/"), &reqBody)
+	if err != nil {
+		return err
+	}
+
+	if v.token != "" {
+		req.Header.Set("X-Vault-Token", v.token)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault request %v %v failed with status %v", method, path, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(res)
+}
+
+func (v *vaultClient) login() error {
+	if v.conf.Token != "" {
+		v.token = v.conf.Token
+		return nil
+	}
+
+	if v.conf.RoleID == "" {
+		return fmt.Errorf("vault token or role ID is required")
+	}
+
+	var res struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+
+	err := v.request("POST", "auth/approle/login", map[string]string{
+		"role_id":   v.conf.RoleID,
+		"secret_id": v.conf.SecretID,
+	}, &res)
+	if err != nil {
+		return err
+	}
+
+	v.token = res.Auth.ClientToken
+	return nil
+}
+
+// getSecret returns value of the key of the secret at SecretPath.
+// Secret is read once, both KV v1 and KV v2 engines are supported.
+func (v *vaultClient) getSecret(key string) (string, error) {
+	if v.secret == nil {
+		if err := v.login(); err != nil {
+			return "", err
+		}
+
+		var res struct {
+			Data map[string]interface{} `json:"data"`
+		}
+
+		if err := v.request("GET", v.conf.SecretPath, nil, &res); err != nil {
+			return "", err
+		}
+
+		v.secret = res.Data
+
+		// KV v2 wraps secret into data with metadata
+		if data, ok := res.Data["data"].(map[string]interface{}); ok {
+			if _, hasMetadata := res.Data["metadata"]; hasMetadata {
+				v.secret = data
+			}
+		}
+	}
+
+	value, ok := v.secret[key].(string)
+	if !ok {
+		return "", fmt.Errorf("vault secret %v doesn't contain string key %v", v.conf.SecretPath, key)
+	}
+
+	return value, nil
+}
+
+// resolveVaultSecrets replaces config values with `vault:` prefix
+// by the values of the secret from Vault.
+func resolveVaultSecrets(conf *ConfigType) error {
+	transport, err := conf.NewHTTPTransport()
+	if err != nil {
+		return err
+	}
+
+	vault := &vaultClient{
+		conf:   conf.Vault,
+		client: &http.Client{Timeout: 10 * time.Second, Transport: transport},
+	}
+
+	return resolveVaultSecretsInObject(vault, reflect.ValueOf(conf).Elem(), "")
+}
+
+func resolveVaultSecretsInObject(vault *vaultClient, v reflect.Value, fieldName string) error {
+	switch v.Kind() {
+	case reflect.String:
+		if !strings.HasPrefix(v.String(), vaultRefPrefix) || !v.CanSet() {
+			return nil
+		}
+
+		if vault.conf.Address == "" {
+			return fmt.Errorf("value of field '%v' references vault secret, but vault address is not set", fieldName)
+		}
+
+		value, err := vault.getSecret(strings.TrimPrefix(v.String(), vaultRefPrefix))
+		if err != nil {
+			return fmt.Errorf("can't read value of field '%v' from vault: %v", fieldName, err)
+		}
+
+		v.SetString(value)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if !v.Field(i).CanSet() || t.Field(i).Name == "Vault" {
+				continue
+			}
+
+			name := t.Field(i).Name
+			if fieldName != "" {
+				name = fieldName + "." + name
+			}
+
+			if err := resolveVaultSecretsInObject(vault, v.Field(i), name); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			val := reflect.New(v.Type().Elem()).Elem()
+			val.Set(v.MapIndex(key))
+
+			if err := resolveVaultSecretsInObject(vault, val, fmt.Sprintf("%v[%v]", fieldName, key)); err != nil {
+				return err
+			}
+
+			v.SetMapIndex(key, val)
+		}
+	}
+
+	return nil
+}
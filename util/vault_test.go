@@ -0,0 +1,84 @@
+package util
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestVaultServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var res interface{}
+
+		switch r.URL.Path {
+		case "/v1/auth/approle/login":
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["role_id"] != "role" || body["secret_id"] != "secret" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			res = map[string]interface{}{"auth": map[string]string{"client_token": "approle-token"}}
+		case "/v1/secret/data/semaphore":
+			token := r.Header.Get("X-Vault-Token")
+			if token != "root-token" && token != "approle-token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			res = map[string]interface{}{
+				"data": map[string]interface{}{
+					"data":     map[string]string{"db_password": "db_secret", "cookie_hash": "hash_secret"},
+					"metadata": map[string]interface{}{"version": 1},
+				},
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(res); err != nil {
+			t.Error(err)
+		}
+	}))
+}
+
+func TestResolveVaultSecrets(t *testing.T) {
+	server := newTestVaultServer(t)
+	defer server.Close()
+
+	for _, vault := range []VaultConfig{
+		{Address: server.URL, Token: "root-token", SecretPath: "secret/data/semaphore"},
+		{Address: server.URL, RoleID: "role", SecretID: "secret", SecretPath: "secret/data/semaphore"},
+	} {
+		conf := &ConfigType{
+			Vault:      vault,
+			CookieHash: "vault:cookie_hash",
+			MySQL:      DbConfig{Password: "vault:db_password", Username: "semaphore"},
+		}
+
+		if err := resolveVaultSecrets(conf); err != nil {
+			t.Fatal(err)
+		}
+
+		if conf.MySQL.Password != "db_secret" || conf.CookieHash != "hash_secret" {
+			t.Errorf("Secrets were not resolved: %v, %v", conf.MySQL.Password, conf.CookieHash)
+		}
+		if conf.MySQL.Username != "semaphore" {
+			t.Errorf("Value without vault prefix was changed: %v", conf.MySQL.Username)
+		}
+	}
+
+	conf := &ConfigType{
+		Vault:      VaultConfig{Address: server.URL, Token: "root-token", SecretPath: "secret/data/semaphore"},
+		CookieHash: "vault:missing",
+	}
+	if err := resolveVaultSecrets(conf); err == nil || !strings.Contains(err.Error(), "CookieHash") {
+		t.Errorf("Expected error for missing key, got %v", err)
+	}
+
+	conf = &ConfigType{CookieHash: "vault:cookie_hash"}
+	if err := resolveVaultSecrets(conf); err == nil {
+		t.Error("Expected error for vault reference without vault address")
+	}
+}